@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/timetravel"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"golang.org/x/sys/unix"
+)
+
+type clockEventAction struct{}
+
+type ClockEventActionState struct {
+	LeapSecond   bool
+	LeapSecondIn time.Duration
+	Timezone     string
+
+	LeapSecondApplied bool
+	OriginalStatus    int32
+	ClockStepped      bool
+	Reference         timetravel.Reference
+	TimezoneApplied   bool
+	OriginalLocaltime timetravel.LocaltimeBackup
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[ClockEventActionState]         = (*clockEventAction)(nil)
+	_ action_kit_sdk.ActionWithStop[ClockEventActionState] = (*clockEventAction)(nil)
+)
+
+func NewClockEventAction() action_kit_sdk.Action[ClockEventActionState] {
+	return &clockEventAction{}
+}
+
+func (a *clockEventAction) NewEmptyState() ClockEventActionState {
+	return ClockEventActionState{}
+}
+
+func (a *clockEventAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.clock-event", BaseActionID),
+		Label:       "Clock Event",
+		Description: "Inserts a leap second and/or switches the timezone of the host for the given duration.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(timeTravelIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("State"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the clock event last?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("60s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:         "leapSecond",
+				Label:        "Insert Leap Second",
+				Description:  extutil.Ptr("Insert a leap second, the clock repeats 23:59:59 UTC."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: extutil.Ptr("true"),
+				Required:     extutil.Ptr(false),
+				Order:        extutil.Ptr(2),
+			},
+			{
+				Name:          "leapSecondIn",
+				Label:         "Leap Second In",
+				Description:   extutil.Ptr("When to insert the leap second. The clock is set to shortly before midnight UTC and restored to the correct time at the end. With 0 the clock isn't changed and the leap second is inserted at the next midnight UTC, which must be within the duration."),
+				Type:          action_kit_api.ActionParameterTypeDuration,
+				DurationUnits: extutil.Ptr([]action_kit_api.DurationUnit{action_kit_api.DurationUnitSeconds, action_kit_api.DurationUnitMinutes}),
+				DefaultValue:  extutil.Ptr("10s"),
+				Required:      extutil.Ptr(false),
+				Order:         extutil.Ptr(3),
+			},
+			{
+				Name:        "timezone",
+				Label:       "Timezone",
+				Description: extutil.Ptr("Switch /etc/localtime of the host to the timezone, e.g. America/New_York. Leave empty to keep the timezone. Processes with a TZ environment variable are not affected."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(4),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *clockEventAction) Prepare(_ context.Context, state *ClockEventActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	state.LeapSecond = extutil.ToBool(request.Config["leapSecond"])
+	state.LeapSecondIn = time.Duration(extutil.ToInt64(request.Config["leapSecondIn"])) * time.Millisecond
+	state.Timezone = strings.TrimSpace(extutil.ToString(request.Config["timezone"]))
+	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
+
+	var refusal error
+	if !state.LeapSecond && state.Timezone == "" {
+		refusal = errors.New("neither a leap second nor a timezone is configured")
+	} else if state.LeapSecond {
+		refusal = checkLeapSecondWithin(time.Now(), state.LeapSecondIn, duration)
+	}
+	if refusal == nil && state.Timezone != "" {
+		refusal = timetravel.CheckTimezone(state.Timezone)
+	}
+	if refusal != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Invalid clock event",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(refusal.Error()),
+			}),
+		}, nil
+	}
+	return nil, nil
+}
+
+// checkLeapSecondWithin checks that the leap second is inserted before the end of the attack
+func checkLeapSecondWithin(now time.Time, leapSecondIn, duration time.Duration) error {
+	if leapSecondIn < 0 {
+		return fmt.Errorf("leap second in must not be negative")
+	}
+	if leapSecondIn == 0 {
+		leapSecondIn = timetravel.NextLeapSecond(now).Sub(now)
+	}
+	if leapSecondIn >= duration {
+		return fmt.Errorf("the leap second would be inserted in %s, after the duration of %s", leapSecondIn.Round(time.Second), duration)
+	}
+	return nil
+}
+
+func (a *clockEventAction) Start(ctx context.Context, state *ClockEventActionState) (*action_kit_api.StartResult, error) {
+	var messages []action_kit_api.Message
+
+	if state.Timezone != "" {
+		original, err := timetravel.BackupLocaltime()
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to read the timezone of the host", err)
+		}
+		state.OriginalLocaltime = original
+		if err := timetravel.SetTimezone(ctx, state.Timezone); err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to switch the timezone to %s", state.Timezone), err)
+		}
+		state.TimezoneApplied = true
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Switched the timezone from %s to %s", original.Timezone(), state.Timezone),
+		})
+	}
+
+	if state.LeapSecond {
+		message, err := a.startLeapSecond(state)
+		if err != nil {
+			if _, stopErr := a.Stop(ctx, state); stopErr != nil {
+				log.Error().Err(stopErr).Msg("Failed to revert clock event")
+			}
+			return nil, extension_kit.ToError("Failed to insert leap second", err)
+		}
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: message,
+		})
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr(messages),
+	}, nil
+}
+
+// startLeapSecond sets the clock shortly before midnight UTC, if requested, and arms the leap second
+func (a *clockEventAction) startLeapSecond(state *ClockEventActionState) (string, error) {
+	leapAt := timetravel.NextLeapSecond(time.Now())
+	if state.LeapSecondIn > 0 {
+		reference, err := timetravel.NewReference(unix.CLOCK_MONOTONIC)
+		if err != nil {
+			return "", err
+		}
+		state.Reference = reference
+		if err := timetravel.SetTime(leapAt.Add(-state.LeapSecondIn)); err != nil {
+			return "", err
+		}
+		state.ClockStepped = true
+	}
+
+	original, err := timetravel.InsertLeapSecond()
+	if err != nil {
+		return "", err
+	}
+	state.OriginalStatus = original
+	state.LeapSecondApplied = true
+	return fmt.Sprintf("Inserting a leap second at %s", leapAt.Format(time.RFC3339)), nil
+}
+
+func (a *clockEventAction) Stop(ctx context.Context, state *ClockEventActionState) (*action_kit_api.StopResult, error) {
+	if !state.LeapSecondApplied && !state.ClockStepped && !state.TimezoneApplied {
+		log.Debug().Msg("No clock event applied, skipping revert")
+		return nil, nil
+	}
+
+	var errs []error
+	var messages []action_kit_api.Message
+	if state.LeapSecondApplied {
+		if err := timetravel.ClearLeapSecond(state.OriginalStatus); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.LeapSecondApplied = false
+		}
+	}
+	if state.ClockStepped {
+		if step, residual, err := timetravel.RestoreTime(state.Reference); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.ClockStepped = false
+			messages = append(messages, action_kit_api.Message{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Stepped the clock by %s to the correct time, residual error %s", step.Round(time.Millisecond), residual),
+			})
+		}
+	}
+	if state.TimezoneApplied {
+		if err := timetravel.RestoreLocaltime(ctx, state.OriginalLocaltime); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.TimezoneApplied = false
+			messages = append(messages, action_kit_api.Message{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Restored the timezone %s", state.OriginalLocaltime.Timezone()),
+			})
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, extension_kit.ToError("Failed to revert clock event", err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr(messages),
+	}, nil
+}
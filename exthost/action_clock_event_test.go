@@ -0,0 +1,16 @@
+package exthost
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_checkLeapSecondWithin(t *testing.T) {
+	beforeMidnight := time.Date(2025, 6, 30, 23, 58, 0, 0, time.UTC)
+	assert.NoError(t, checkLeapSecondWithin(beforeMidnight, 10*time.Second, time.Minute))
+	assert.ErrorContains(t, checkLeapSecondWithin(beforeMidnight, 2*time.Minute, time.Minute), "after the duration")
+	assert.NoError(t, checkLeapSecondWithin(beforeMidnight, 0, 5*time.Minute))
+	assert.ErrorContains(t, checkLeapSecondWithin(beforeMidnight, 0, time.Minute), "in 2m0s")
+	assert.ErrorContains(t, checkLeapSecondWithin(beforeMidnight, -time.Second, time.Minute), "must not be negative")
+}
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/filecorrupt"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// protectedFilePaths must never be corrupted, as they are not backed by regular files or belong to the extension.
+var protectedFilePaths = []string{"/proc", "/sys", "/opt/steadybit"}
+
+var extensionExecutable = os.Executable
+
+type corruptFileAction struct{}
+
+type CorruptFileActionState struct {
+	Mode       filecorrupt.Mode
+	Path       string
+	BackupPath string
+	Backup     filecorrupt.Backup
+	Applied    bool
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[CorruptFileActionState]         = (*corruptFileAction)(nil)
+	_ action_kit_sdk.ActionWithStop[CorruptFileActionState] = (*corruptFileAction)(nil)
+)
+
+func NewCorruptFileAction() action_kit_sdk.Action[CorruptFileActionState] {
+	return &corruptFileAction{}
+}
+
+func (a *corruptFileAction) NewEmptyState() CorruptFileActionState {
+	return CorruptFileActionState{}
+}
+
+func (a *corruptFileAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.corrupt-file", BaseActionID),
+		Label:       "Corrupt File",
+		Description: "Deletes, truncates or overwrites a file of the host with random bytes for the given duration and restores the original afterwards.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(corruptIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("Resource"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the file be corrupted?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:        "path",
+				Label:       "File",
+				Description: extutil.Ptr("A regular file on the host. A backup is stored next to it during the attack."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(true),
+				Order:       extutil.Ptr(2),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  extutil.Ptr("*Delete:* Remove the file.\n\n*Truncate:* Truncate the file to zero bytes.\n\n*Corrupt:* Overwrite the content with random bytes, keeping the size."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(string(filecorrupt.ModeDelete)),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(3),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Delete",
+						Value: string(filecorrupt.ModeDelete),
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Truncate",
+						Value: string(filecorrupt.ModeTruncate),
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Corrupt",
+						Value: string(filecorrupt.ModeCorrupt),
+					},
+				}),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *corruptFileAction) Prepare(_ context.Context, state *CorruptFileActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	mode := filecorrupt.Mode(extutil.ToString(request.Config["mode"]))
+	switch mode {
+	case filecorrupt.ModeDelete, filecorrupt.ModeTruncate, filecorrupt.ModeCorrupt:
+	default:
+		return nil, fmt.Errorf("invalid mode %s", mode)
+	}
+
+	path := extutil.ToString(request.Config["path"])
+	var refusal error
+	if !filepath.IsAbs(path) {
+		refusal = fmt.Errorf("path must be absolute, got '%s'", path)
+	} else if resolved, err := hostfs.EvalSymlinks(path); err != nil {
+		refusal = fmt.Errorf("%s does not exist on the host: %w", path, err)
+	} else if refusal = checkCorruptAllowed(resolved); refusal == nil {
+		if fi, err := os.Stat(hostfs.Path(resolved)); err != nil || !fi.Mode().IsRegular() {
+			refusal = fmt.Errorf("%s is not a regular file on the host", resolved)
+		}
+		path = resolved
+	}
+	if refusal != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Cannot corrupt %s", path),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(refusal.Error()),
+			}),
+		}, nil
+	}
+
+	state.Mode = mode
+	state.Path = path
+	state.BackupPath = filecorrupt.BackupPath(path, request.ExecutionId)
+	return nil, nil
+}
+
+// checkCorruptAllowed refuses pseudo filesystems and the extension's own files.
+func checkCorruptAllowed(path string) error {
+	protected := protectedFilePaths
+	if dir := hostInstallDir(); dir != "" {
+		protected = append(protected, dir)
+	}
+	for _, p := range protected {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return fmt.Errorf("files below %s must not be corrupted", p)
+		}
+	}
+	return nil
+}
+
+// hostInstallDir returns the directory of the extension's executable if it runs from the host's filesystem, e.g. when installed as a
+// systemd service. In a container the executable is not a host path and nothing is returned.
+func hostInstallDir() string {
+	exe, err := extensionExecutable()
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Dir(exe)
+	if dir == "/" {
+		return ""
+	}
+	own, err := os.Stat(exe)
+	if err != nil {
+		return ""
+	}
+	onHost, err := os.Stat(hostfs.Path(exe))
+	if err != nil || !os.SameFile(own, onHost) {
+		return ""
+	}
+	return dir
+}
+
+func (a *corruptFileAction) Start(ctx context.Context, state *CorruptFileActionState) (*action_kit_api.StartResult, error) {
+	backup, err := filecorrupt.Create(ctx, state.Path, state.BackupPath)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to back up %s", state.Path), err)
+	}
+	state.Backup = backup
+	state.Applied = true
+
+	if err := filecorrupt.Apply(ctx, backup, state.Mode); err != nil {
+		if restoreErr := filecorrupt.Restore(ctx, backup); restoreErr != nil {
+			err = errors.Join(err, restoreErr)
+		} else {
+			state.Applied = false
+		}
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to corrupt %s", state.Path), err)
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Corrupted %s (%s), the original is kept at %s", state.Path, strings.ToLower(string(state.Mode)), state.BackupPath),
+			},
+		}),
+	}, nil
+}
+
+func (a *corruptFileAction) Stop(ctx context.Context, state *CorruptFileActionState) (*action_kit_api.StopResult, error) {
+	if !state.Applied {
+		log.Debug().Msg("No file corrupted, skipping restore")
+		return nil, nil
+	}
+
+	if err := filecorrupt.Restore(ctx, state.Backup); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restore %s", state.Path), err)
+	}
+	state.Applied = false
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Restored %s", state.Path),
+			},
+		}),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package exthost
+
+import (
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_checkCorruptAllowed(t *testing.T) {
+	assert.Error(t, checkCorruptAllowed("/proc/sys/kernel/hostname"))
+	assert.Error(t, checkCorruptAllowed("/sys/kernel/mm/transparent_hugepage/enabled"))
+	assert.Error(t, checkCorruptAllowed("/opt/steadybit/extension-host/extension-host"))
+	assert.NoError(t, checkCorruptAllowed("/etc/app/app.conf"))
+	assert.NoError(t, checkCorruptAllowed("/processes.conf"))
+}
+
+func fakeExecutable(t *testing.T, path string) {
+	original := extensionExecutable
+	extensionExecutable = func() (string, error) { return path, nil }
+	t.Cleanup(func() { extensionExecutable = original })
+}
+
+func Test_checkCorruptAllowed_container(t *testing.T) {
+	// the executable of the container image, it is not a host path
+	fakeExecutable(t, "/extension")
+
+	assert.NoError(t, checkCorruptAllowed("/etc/app/app.conf"))
+	assert.NoError(t, checkCorruptAllowed("/var/lib/app/data"))
+	assert.Error(t, checkCorruptAllowed("/proc/sys/kernel/hostname"))
+}
+
+func Test_checkCorruptAllowed_hostInstall(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "extension-host")
+	require.NoError(t, os.WriteFile(exe, nil, 0755))
+	fakeExecutable(t, exe)
+	originalRoot := hostfs.RootPath
+	hostfs.RootPath = "/"
+	t.Cleanup(func() { hostfs.RootPath = originalRoot })
+
+	assert.Error(t, checkCorruptAllowed(filepath.Join(dir, "extension-host.conf")))
+	assert.NoError(t, checkCorruptAllowed("/etc/app/app.conf"))
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/cpufreq"
+	"github.com/steadybit/extension-host/exthost/cpuhotplug"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type cpuOfflineAction struct{}
+
+type CpuOfflineActionState struct {
+	Cpus        []int
+	OfflineCpus []int
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[CpuOfflineActionState]         = (*cpuOfflineAction)(nil)
+	_ action_kit_sdk.ActionWithStop[CpuOfflineActionState] = (*cpuOfflineAction)(nil)
+)
+
+func NewCpuOfflineAction() action_kit_sdk.Action[CpuOfflineActionState] {
+	return &cpuOfflineAction{}
+}
+
+func (a *cpuOfflineAction) NewEmptyState() CpuOfflineActionState {
+	return CpuOfflineActionState{}
+}
+
+func (a *cpuOfflineAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.cpu-offline", BaseActionID),
+		Label:       "CPU Offline",
+		Description: "Takes CPUs of the host offline for the given duration, like after a VM resize or a failing core. cpu0 is never taken offline.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(stressCPUIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("Resource"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the CPUs be offline?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:         "count",
+				Label:        "Number of CPUs",
+				Description:  extutil.Ptr("How many CPUs should be taken offline? The online CPUs with the highest numbers are chosen."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("1"),
+				Required:     extutil.Ptr(true),
+				MinValue:     extutil.Ptr(1),
+				Order:        extutil.Ptr(2),
+			},
+			{
+				Name:        "cpus",
+				Label:       "CPUs",
+				Description: extutil.Ptr("The CPUs to take offline, as a list like 2-3,6. Overrides the number of CPUs if set."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(3),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *cpuOfflineAction) Prepare(_ context.Context, state *CpuOfflineActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	cpus, err := cpufreq.ParseCpuList(extutil.ToString(request.Config["cpus"]))
+	if err == nil {
+		cpus, err = cpuhotplug.SelectCpus(extutil.ToInt(request.Config["count"]), cpus)
+	}
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Cannot take CPUs offline",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	state.Cpus = cpus
+	return nil, nil
+}
+
+func (a *cpuOfflineAction) Start(_ context.Context, state *CpuOfflineActionState) (*action_kit_api.StartResult, error) {
+	for _, cpu := range state.Cpus {
+		if err := cpuhotplug.SetOnline(cpu, false); err != nil {
+			if revertErr := bringCpusOnline(state); revertErr != nil {
+				err = errors.Join(err, revertErr)
+			}
+			return nil, extension_kit.ToError("Failed to take CPUs offline", err)
+		}
+		state.OfflineCpus = append(state.OfflineCpus, cpu)
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Took %s offline", cpuListDescription(state.OfflineCpus)),
+			},
+		}),
+	}, nil
+}
+
+func (a *cpuOfflineAction) Stop(_ context.Context, state *CpuOfflineActionState) (*action_kit_api.StopResult, error) {
+	if len(state.OfflineCpus) == 0 {
+		log.Debug().Msg("No CPUs taken offline, skipping revert")
+		return nil, nil
+	}
+
+	cpus := state.OfflineCpus
+	if err := bringCpusOnline(state); err != nil {
+		return nil, extension_kit.ToError("Failed to bring CPUs online", err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Brought %s online", cpuListDescription(cpus)),
+			},
+		}),
+	}, nil
+}
+
+// bringCpusOnline brings all CPUs taken offline back online, continuing if some fail. Only the failing CPUs are left in
+// the state, which is persisted when Start fails, so the Stop after it doesn't touch the others. Changes made in Stop are
+// discarded by the SDK, CPUs failing there are only reported in the error.
+func bringCpusOnline(state *CpuOfflineActionState) error {
+	var errs []error
+	var remaining []int
+	for _, cpu := range state.OfflineCpus {
+		if err := cpuhotplug.SetOnline(cpu, true); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, cpu)
+		}
+	}
+	state.OfflineCpus = remaining
+	return errors.Join(errs...)
+}
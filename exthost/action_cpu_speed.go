@@ -6,7 +6,10 @@ package exthost
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -20,13 +23,26 @@ import (
 type cpuSpeedAction struct{}
 
 type CpuSpeedActionState struct {
-	OriginalMinFreq uint64
-	OriginalMaxFreq uint64
-	NewMinFreq      uint64
-	NewMaxFreq      uint64
-	FreqsApplied    bool
+	Cpus          []int
+	NewMinFreq    uint64
+	NewMaxFreq    uint64
+	AppliedLimits []cpufreq.CPUFrequencyInfo
+	FreqsApplied  bool
+
+	Governor        string
+	OriginalScaling []cpufreq.ScalingSnapshot
+
+	Turbo         string
+	OriginalTurbo bool
+	TurboApplied  bool
 }
 
+const (
+	cpuSpeedKeep         = "KEEP"
+	cpuSpeedTurboEnable  = "ENABLE"
+	cpuSpeedTurboDisable = "DISABLE"
+)
+
 // Make sure action implements all required interfaces
 var (
 	_ action_kit_sdk.Action[CpuSpeedActionState]           = (*cpuSpeedAction)(nil)
@@ -46,7 +62,7 @@ func (a *cpuSpeedAction) Describe() action_kit_api.ActionDescription {
 	return action_kit_api.ActionDescription{
 		Id:          fmt.Sprintf("%s.cpu-speed", BaseActionID),
 		Label:       "Change CPU Frequency",
-		Description: "Changes the CPU frequency limits for all cores for the given duration.",
+		Description: "Changes the CPU frequency limits for all or selected cores for the given duration.",
 		Version:     extbuild.GetSemverVersionStringOrUnknown(),
 		Icon:        extutil.Ptr(changeCPUSpeed),
 		TargetSelection: &action_kit_api.TargetSelection{
@@ -62,9 +78,9 @@ func (a *cpuSpeedAction) Describe() action_kit_api.ActionDescription {
 				Type:  action_kit_api.ComSteadybitWidgetLineChart,
 				Title: "CPU Frequency",
 				Identity: action_kit_api.LineChartWidgetIdentityConfig{
-					MetricName: "response_time",
-					From:       "freq_type",
-					Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeWidgetPerValue,
+					MetricName: "cpu_freq",
+					From:       "cpu",
+					Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeSelect,
 				},
 				Grouping: extutil.Ptr(action_kit_api.LineChartWidgetGroupingConfig{
 					ShowSummary: extutil.Ptr(true),
@@ -106,6 +122,10 @@ func (a *cpuSpeedAction) Describe() action_kit_api.ActionDescription {
 							From:  "freq_type",
 							Title: "Type",
 						},
+						{
+							From:  "cpu",
+							Title: "CPU",
+						},
 					},
 				}),
 			},
@@ -138,6 +158,46 @@ func (a *cpuSpeedAction) Describe() action_kit_api.ActionDescription {
 				Required:     extutil.Ptr(true),
 				Order:        extutil.Ptr(3),
 			},
+			{
+				Name:         "governor",
+				Label:        "Scaling Governor",
+				Description:  extutil.Ptr("The scaling governor to switch to. It must be supported by the CPU frequency driver, e.g. intel_pstate only supports performance and powersave."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(cpuSpeedKeep),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(4),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Keep current", Value: cpuSpeedKeep},
+					action_kit_api.ExplicitParameterOption{Label: "performance", Value: "performance"},
+					action_kit_api.ExplicitParameterOption{Label: "powersave", Value: "powersave"},
+					action_kit_api.ExplicitParameterOption{Label: "ondemand", Value: "ondemand"},
+					action_kit_api.ExplicitParameterOption{Label: "conservative", Value: "conservative"},
+					action_kit_api.ExplicitParameterOption{Label: "schedutil", Value: "schedutil"},
+				}),
+			},
+			{
+				Name:         "turbo",
+				Label:        "Turbo Boost",
+				Description:  extutil.Ptr("Enable or disable turbo boost for all CPUs."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(cpuSpeedKeep),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(5),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Keep current", Value: cpuSpeedKeep},
+					action_kit_api.ExplicitParameterOption{Label: "Disable", Value: cpuSpeedTurboDisable},
+					action_kit_api.ExplicitParameterOption{Label: "Enable", Value: cpuSpeedTurboEnable},
+				}),
+			},
+			{
+				Name:        "cpus",
+				Label:       "CPUs",
+				Description: extutil.Ptr("The CPUs to change, as a list like 0-3,8. All CPUs if empty. The limits are clamped to the hardware range of each CPU."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(6),
+			},
 		},
 		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
 	}
@@ -148,7 +208,18 @@ func (a *cpuSpeedAction) Prepare(_ context.Context, state *CpuSpeedActionState,
 		return nil, err
 	}
 
-	minFreq, maxFreq, err := cpufreq.GetCPUFrequencyInfo()
+	cpus, err := cpufreq.ParseCpuList(extutil.ToString(request.Config["cpus"]))
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Invalid CPU list",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	infos, err := cpufreq.GetCPUFrequencyInfoPerCpu(cpus)
 	if err != nil {
 		return &action_kit_api.PrepareResult{
 			Error: extutil.Ptr(action_kit_api.ActionKitError{
@@ -158,9 +229,9 @@ func (a *cpuSpeedAction) Prepare(_ context.Context, state *CpuSpeedActionState,
 			}),
 		}, nil
 	}
+	minFreq, maxFreq := cpufreq.FrequencyRange(infos)
 
-	state.OriginalMinFreq = minFreq
-	state.OriginalMaxFreq = maxFreq
+	state.Cpus = cpus
 
 	state.NewMinFreq = extutil.ToUInt64(request.Config["minFreq"])
 	state.NewMaxFreq = extutil.ToUInt64(request.Config["maxFreq"])
@@ -195,11 +266,47 @@ func (a *cpuSpeedAction) Prepare(_ context.Context, state *CpuSpeedActionState,
 		}, nil
 	}
 
+	if governor := extutil.ToString(request.Config["governor"]); governor != "" && governor != cpuSpeedKeep {
+		if err := cpufreq.CheckGovernorAvailable(cpus, governor); err != nil {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Governor not available",
+					Status: extutil.Ptr(action_kit_api.Errored),
+					Detail: extutil.Ptr(err.Error()),
+				}),
+			}, nil
+		}
+		state.Governor = governor
+	}
+
+	switch turbo := extutil.ToString(request.Config["turbo"]); turbo {
+	case "", cpuSpeedKeep:
+	case cpuSpeedTurboEnable, cpuSpeedTurboDisable:
+		if _, err := cpufreq.GetTurboEnabled(); err != nil {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Turbo boost control is not supported on this host",
+					Status: extutil.Ptr(action_kit_api.Errored),
+					Detail: extutil.Ptr(err.Error()),
+				}),
+			}, nil
+		}
+		state.Turbo = turbo
+	default:
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Invalid turbo setting %s", turbo),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(fmt.Sprintf("Turbo must be one of %s, %s or %s", cpuSpeedKeep, cpuSpeedTurboEnable, cpuSpeedTurboDisable)),
+			}),
+		}, nil
+	}
+
 	return &action_kit_api.PrepareResult{
 		Messages: extutil.Ptr([]action_kit_api.Message{
 			{
 				Level:   extutil.Ptr(action_kit_api.Info),
-				Message: fmt.Sprintf("Prepared CPU frequency limits to min=%d MHz, max=%d MHz", state.NewMinFreq, state.NewMaxFreq),
+				Message: fmt.Sprintf("Prepared %s for %s", cpuSpeedDescription(state), cpuListDescription(state.Cpus)),
 			},
 		}),
 	}, nil
@@ -209,120 +316,215 @@ func (a *cpuSpeedAction) Start(_ context.Context, state *CpuSpeedActionState) (*
 	log.Info().
 		Uint64("min_freq", state.NewMinFreq).
 		Uint64("max_freq", state.NewMaxFreq).
+		Str("governor", state.Governor).
+		Str("turbo", state.Turbo).
+		Ints("cpus", state.Cpus).
 		Msg("Setting CPU frequency limits")
 
-	if err := cpufreq.SetCPUFrequencyLimits(state.NewMinFreq, state.NewMaxFreq); err != nil {
+	if err := applyCpuSpeed(state); err != nil {
 		log.Error().Err(err).Msg("Failed to set CPU frequency limits")
+		if revertErr := revertCpuSpeed(state); revertErr != nil {
+			log.Error().Err(revertErr).Msg("Failed to revert partially applied CPU frequency settings")
+			return nil, errors.Join(err, revertErr)
+		}
 		return nil, err
 	}
 
-	currentFreq, err := cpufreq.GetCurrentFrequency()
+	currentFreqs, err := cpufreq.GetCurrentFrequencies(state.Cpus)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get current CPU frequency")
 		return nil, err
 	}
 
-	state.FreqsApplied = true
-	now := time.Now()
 	return &action_kit_api.StartResult{
-		Metrics: extutil.Ptr([]action_kit_api.Metric{
-			{
-				Name: extutil.Ptr("cpu_freq"),
-				Metric: map[string]string{
-					"freq_type": "Current",
-				},
-				Value:     float64(currentFreq),
-				Timestamp: now,
-			},
-			{
-				Name: extutil.Ptr("cpu_freq"),
-				Metric: map[string]string{
-					"freq_type": "Minimum",
-				},
-				Value:     float64(state.NewMinFreq),
-				Timestamp: now,
-			},
-			{
-				Name: extutil.Ptr("cpu_freq"),
-				Metric: map[string]string{
-					"freq_type": "Maximum",
-				},
-				Value:     float64(state.NewMaxFreq),
-				Timestamp: now,
-			},
-		}),
+		Metrics: extutil.Ptr(cpuSpeedMetrics(state.AppliedLimits, currentFreqs, time.Now())),
 		Messages: extutil.Ptr([]action_kit_api.Message{
 			{
 				Level:   extutil.Ptr(action_kit_api.Info),
-				Message: fmt.Sprintf("Set CPU frequency limits to min=%d MHz, max=%d MHz", state.NewMinFreq, state.NewMaxFreq),
+				Message: fmt.Sprintf("Set %s for %s", cpuSpeedDescription(state), cpuListDescription(state.Cpus)),
 			},
 		}),
 	}, nil
 }
 
+// applyCpuSpeed applies governor, frequency limits and turbo boost, recording the originals in the state for the revert
+func applyCpuSpeed(state *CpuSpeedActionState) error {
+	originals, err := cpufreq.SnapshotScaling(state.Cpus)
+	if err != nil {
+		return err
+	}
+	state.OriginalScaling = originals
+	state.FreqsApplied = true
+
+	if state.Governor != "" {
+		governors := make([]cpufreq.CPUGovernor, 0, len(originals))
+		for _, original := range originals {
+			governors = append(governors, cpufreq.CPUGovernor{Cpu: original.Cpu, Governor: state.Governor})
+		}
+		if err := cpufreq.SetGovernors(governors); err != nil {
+			return err
+		}
+	}
+
+	applied, err := cpufreq.SetCPUFrequencyLimitsForCpus(state.Cpus, state.NewMinFreq, state.NewMaxFreq)
+	state.AppliedLimits = applied
+	if err != nil {
+		return err
+	}
+
+	if state.Turbo != "" {
+		original, err := cpufreq.GetTurboEnabled()
+		if err != nil {
+			return err
+		}
+		state.OriginalTurbo = original
+		state.TurboApplied = true
+		if err := cpufreq.SetTurboEnabled(state.Turbo == cpuSpeedTurboEnable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertCpuSpeed restores everything applied, continuing on errors to restore as much as possible
+func revertCpuSpeed(state *CpuSpeedActionState) error {
+	var errs []error
+	if state.TurboApplied {
+		if err := cpufreq.SetTurboEnabled(state.OriginalTurbo); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.TurboApplied = false
+		}
+	}
+	if state.FreqsApplied {
+		if err := cpufreq.RestoreScaling(state.OriginalScaling); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.FreqsApplied = false
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (a *cpuSpeedAction) Stop(_ context.Context, state *CpuSpeedActionState) (*action_kit_api.StopResult, error) {
-	if !state.FreqsApplied {
+	if !state.FreqsApplied && !state.TurboApplied {
 		log.Debug().Msg("No frequency limits applied, skipping revert")
 		return nil, nil
 	}
 
 	log.Info().
-		Uint64("min_freq", state.OriginalMinFreq).
-		Uint64("max_freq", state.OriginalMaxFreq).
+		Ints("cpus", state.Cpus).
 		Msg("Restoring original CPU frequency limits")
 
-	if err := cpufreq.SetCPUFrequencyLimits(state.OriginalMinFreq, state.OriginalMaxFreq); err != nil {
+	if err := revertCpuSpeed(state); err != nil {
 		log.Error().Err(err).Msg("Failed to restore CPU frequency limits")
 		return nil, err
 	}
 
-	state.FreqsApplied = false
+	messages := []action_kit_api.Message{
+		{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Restored original CPU frequency scaling %s", scalingDescription(state.OriginalScaling)),
+		},
+	}
+	if state.Turbo != "" {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Restored turbo boost to %s", enabledDescription(state.OriginalTurbo)),
+		})
+	}
 	return &action_kit_api.StopResult{
-		Messages: extutil.Ptr([]action_kit_api.Message{
-			{
-				Level:   extutil.Ptr(action_kit_api.Info),
-				Message: fmt.Sprintf("Restored CPU frequency limits to min=%d MHz, max=%d MHz", state.OriginalMinFreq, state.OriginalMaxFreq),
-			},
-		}),
+		Messages: extutil.Ptr(messages),
 	}, nil
 }
 
 // Status is called to get the current status of the action
 func (a *cpuSpeedAction) Status(_ context.Context, state *CpuSpeedActionState) (*action_kit_api.StatusResult, error) {
-	currentFreq, err := cpufreq.GetCurrentFrequency()
+	currentFreqs, err := cpufreq.GetCurrentFrequencies(state.Cpus)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get current CPU frequency")
 		return nil, err
 	}
 
-	now := time.Now()
 	return &action_kit_api.StatusResult{
 		Completed: false,
-		Metrics: extutil.Ptr([]action_kit_api.Metric{
-			{
-				Name: extutil.Ptr("cpu_freq"),
-				Metric: map[string]string{
-					"freq_type": "Current",
-				},
-				Value:     float64(currentFreq),
-				Timestamp: now,
-			},
-			{
-				Name: extutil.Ptr("cpu_freq"),
-				Metric: map[string]string{
-					"freq_type": "Minimum",
-				},
-				Value:     float64(state.NewMinFreq),
-				Timestamp: now,
-			},
-			{
-				Name: extutil.Ptr("cpu_freq"),
-				Metric: map[string]string{
-					"freq_type": "Maximum",
-				},
-				Value:     float64(state.NewMaxFreq),
-				Timestamp: now,
-			},
-		}),
+		Metrics:   extutil.Ptr(cpuSpeedMetrics(state.AppliedLimits, currentFreqs, time.Now())),
 	}, nil
 }
+
+// cpuSpeedMetrics returns the current frequency and the applied limits of each CPU
+func cpuSpeedMetrics(limits []cpufreq.CPUFrequencyInfo, currentFreqs map[int]uint64, now time.Time) []action_kit_api.Metric {
+	metrics := make([]action_kit_api.Metric, 0, len(limits)*3)
+	for _, limit := range limits {
+		cpu := fmt.Sprintf("cpu%d", limit.Cpu)
+		if currentFreq, ok := currentFreqs[limit.Cpu]; ok {
+			metrics = append(metrics, cpuFreqMetric(cpu, "Current", currentFreq, now))
+		}
+		metrics = append(metrics,
+			cpuFreqMetric(cpu, "Minimum", limit.MinFreq, now),
+			cpuFreqMetric(cpu, "Maximum", limit.MaxFreq, now),
+		)
+	}
+	return metrics
+}
+
+func cpuFreqMetric(cpu, freqType string, value uint64, now time.Time) action_kit_api.Metric {
+	return action_kit_api.Metric{
+		Name: extutil.Ptr("cpu_freq"),
+		Metric: map[string]string{
+			"cpu":       cpu,
+			"freq_type": freqType,
+		},
+		Value:     float64(value),
+		Timestamp: now,
+	}
+}
+
+func cpuSpeedDescription(state *CpuSpeedActionState) string {
+	description := fmt.Sprintf("CPU frequency limits to min=%d MHz, max=%d MHz", state.NewMinFreq, state.NewMaxFreq)
+	if state.Governor != "" {
+		description += fmt.Sprintf(", governor %s", state.Governor)
+	}
+	if state.Turbo != "" {
+		description += fmt.Sprintf(", turbo boost %s", enabledDescription(state.Turbo == cpuSpeedTurboEnable))
+	}
+	return description
+}
+
+// scalingDescription describes the scaling settings per CPU, merging CPUs with the same settings
+func scalingDescription(snapshots []cpufreq.ScalingSnapshot) string {
+	var settings []string
+	cpusBySetting := make(map[string][]int)
+	for _, snapshot := range snapshots {
+		minFreq, _ := strconv.ParseUint(strings.TrimSpace(snapshot.MinFreq), 10, 64)
+		maxFreq, _ := strconv.ParseUint(strings.TrimSpace(snapshot.MaxFreq), 10, 64)
+		setting := fmt.Sprintf("min=%d MHz, max=%d MHz, governor %s", minFreq/1000, maxFreq/1000, strings.TrimSpace(snapshot.Governor))
+		if _, ok := cpusBySetting[setting]; !ok {
+			settings = append(settings, setting)
+		}
+		cpusBySetting[setting] = append(cpusBySetting[setting], snapshot.Cpu)
+	}
+	parts := make([]string, 0, len(settings))
+	for _, setting := range settings {
+		parts = append(parts, fmt.Sprintf("%s for %s", setting, cpuListDescription(cpusBySetting[setting])))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func enabledDescription(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func cpuListDescription(cpus []int) string {
+	if len(cpus) == 0 {
+		return "all CPUs"
+	}
+	names := make([]string, 0, len(cpus))
+	for _, cpu := range cpus {
+		names = append(names, fmt.Sprintf("cpu%d", cpu))
+	}
+	return strings.Join(names, ", ")
+}
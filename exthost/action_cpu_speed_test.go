@@ -0,0 +1,24 @@
+package exthost
+
+import (
+	"github.com/steadybit/extension-host/exthost/cpufreq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_cpuSpeedDescription(t *testing.T) {
+	state := &CpuSpeedActionState{NewMinFreq: 800, NewMaxFreq: 1200}
+	assert.Equal(t, "CPU frequency limits to min=800 MHz, max=1200 MHz", cpuSpeedDescription(state))
+
+	state.Governor = "powersave"
+	state.Turbo = cpuSpeedTurboDisable
+	assert.Equal(t, "CPU frequency limits to min=800 MHz, max=1200 MHz, governor powersave, turbo boost disabled", cpuSpeedDescription(state))
+}
+
+func Test_scalingDescription(t *testing.T) {
+	assert.Equal(t, "min=1200 MHz, max=3900 MHz, governor schedutil for cpu0, cpu2; min=800 MHz, max=4800 MHz, governor performance for cpu1", scalingDescription([]cpufreq.ScalingSnapshot{
+		{Cpu: 0, MinFreq: "1200000\n", MaxFreq: "3900000\n", Governor: "schedutil\n"},
+		{Cpu: 1, MinFreq: "800000\n", MaxFreq: "4800000\n", Governor: "performance\n"},
+		{Cpu: 2, MinFreq: "1200000\n", MaxFreq: "3900000\n", Governor: "schedutil\n"},
+	}))
+}
@@ -8,19 +8,29 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_commons/diskfill"
 	"github.com/steadybit/action-kit/go/action_kit_commons/ociruntime"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/extension-host/config"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-host/exthost/inodefill"
+	"github.com/steadybit/extension-host/exthost/mounts"
 	extension_kit "github.com/steadybit/extension-kit"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
 	"golang.org/x/sync/syncmap"
+	"golang.org/x/sys/unix"
+	"path/filepath"
+	"time"
 )
 
 var ID = fmt.Sprintf("%s.fill_disk", BaseActionID)
 
+// fillDiskModeInodes fills the inodes instead of the space of the filesystem.
+const fillDiskModeInodes diskfill.Mode = "INODES"
+
 type fillDiskAction struct {
 	ociRuntime ociruntime.OciRuntime
 	diskfills  syncmap.Map
@@ -30,6 +40,9 @@ type FillDiskActionState struct {
 	ExecutionId  uuid.UUID
 	Sidecar      diskfill.SidecarOpts
 	FillDiskOpts diskfill.Opts
+	MountPoint   string
+	FsType       string
+	Device       string
 }
 
 // Make sure fillDiskAction implements all required interfaces
@@ -62,6 +75,78 @@ func (a *fillDiskAction) Describe() action_kit_api.ActionDescription {
 		Category:    extutil.Ptr("Resource"),
 		Kind:        action_kit_api.Attack,
 		TimeControl: action_kit_api.TimeControlExternal,
+		Widgets: extutil.Ptr([]action_kit_api.Widget{
+			action_kit_api.LineChartWidget{
+				Type:  action_kit_api.ComSteadybitWidgetLineChart,
+				Title: "Filesystem Usage",
+				Identity: action_kit_api.LineChartWidgetIdentityConfig{
+					MetricName: "fs_usage",
+					From:       "usage_type",
+					Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeWidgetPerValue,
+				},
+				Grouping: extutil.Ptr(action_kit_api.LineChartWidgetGroupingConfig{
+					ShowSummary: extutil.Ptr(true),
+					Groups: []action_kit_api.LineChartWidgetGroup{
+						{
+							Title: "Used",
+							Color: "warn",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "usage_type",
+								Value: "Used",
+							},
+						},
+						{
+							Title: "Free",
+							Color: "success",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "usage_type",
+								Value: "Free",
+							},
+						},
+						{
+							Title: "Inodes Used",
+							Color: "info",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "usage_type",
+								Value: "Inodes Used",
+							},
+						},
+					},
+				}),
+				Tooltip: extutil.Ptr(action_kit_api.LineChartWidgetTooltipConfig{
+					MetricValueTitle: extutil.Ptr("Usage"),
+					MetricValueUnit:  extutil.Ptr("%"),
+					AdditionalContent: []action_kit_api.LineChartWidgetTooltipContent{
+						{
+							From:  "path",
+							Title: "Path",
+						},
+					},
+				}),
+			},
+			action_kit_api.LineChartWidget{
+				Type:  action_kit_api.ComSteadybitWidgetLineChart,
+				Title: "Written",
+				Identity: action_kit_api.LineChartWidgetIdentityConfig{
+					MetricName: "fill_disk_written",
+					From:       "path",
+					Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeWidgetPerValue,
+				},
+				Tooltip: extutil.Ptr(action_kit_api.LineChartWidgetTooltipConfig{
+					MetricValueTitle: extutil.Ptr("Written"),
+					MetricValueUnit:  extutil.Ptr("MiB"),
+					AdditionalContent: []action_kit_api.LineChartWidgetTooltipContent{
+						{
+							From:  "path",
+							Title: "Path",
+						},
+					},
+				}),
+			},
+		}),
 		Parameters: []action_kit_api.ActionParameter{
 			{
 				Name:         "duration",
@@ -75,7 +160,7 @@ func (a *fillDiskAction) Describe() action_kit_api.ActionDescription {
 			{
 				Name:         "mode",
 				Label:        "Mode",
-				Description:  extutil.Ptr("Decide how to specify the amount to fill the disk:\n\noverall percentage of filled disk space in percent,\n\nMegabytes to write,\n\nMegabytes to leave free on disk"),
+				Description:  extutil.Ptr("Decide how to specify the amount to fill the disk:\n\noverall percentage of filled disk space in percent,\n\nMegabytes to write,\n\nMegabytes to leave free on disk,\n\noverall percentage of used inodes in percent by creating empty files"),
 				Required:     extutil.Ptr(true),
 				Order:        extutil.Ptr(2),
 				DefaultValue: extutil.Ptr("PERCENTAGE"),
@@ -93,12 +178,16 @@ func (a *fillDiskAction) Describe() action_kit_api.ActionDescription {
 						Label: "Megabytes to leave free on disk",
 						Value: string(diskfill.MBLeft),
 					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Overall percentage of used inodes in percent",
+						Value: string(fillDiskModeInodes),
+					},
 				}),
 			},
 			{
 				Name:         "size",
 				Label:        "Fill Value (depending on Mode)",
-				Description:  extutil.Ptr("Depending on the mode, specify the percentage of filled disk space or the number of Megabytes to be written or left free."),
+				Description:  extutil.Ptr("Depending on the mode, specify the percentage of filled disk space or used inodes, or the number of Megabytes to be written or left free."),
 				Type:         action_kit_api.ActionParameterTypeInteger,
 				DefaultValue: extutil.Ptr("80"),
 				Required:     extutil.Ptr(true),
@@ -107,11 +196,16 @@ func (a *fillDiskAction) Describe() action_kit_api.ActionDescription {
 			{
 				Name:         "path",
 				Label:        "File Destination",
-				Description:  extutil.Ptr("Where to temporarily write the file for filling the disk. It will be cleaned up afterwards."),
+				Description:  extutil.Ptr("Where to temporarily write the file (or the files when filling inodes) for filling the disk. It will be cleaned up afterwards."),
 				Type:         action_kit_api.ActionParameterTypeString,
-				DefaultValue: extutil.Ptr("/tmp"),
+				DefaultValue: extutil.Ptr("/var/tmp"),
 				Required:     extutil.Ptr(true),
 				Order:        extutil.Ptr(4),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ParameterOptionsFromTargetAttribute{
+						Attribute: "host.mount_point",
+					},
+				}),
 			},
 			{
 				Name:         "method",
@@ -163,6 +257,11 @@ func fillDiskOpts(request action_kit_api.PrepareActionRequestBody) (diskfill.Opt
 		opts.Mode = diskfill.MBToFill
 	case string(diskfill.MBLeft):
 		opts.Mode = diskfill.MBLeft
+	case string(fillDiskModeInodes):
+		opts.Mode = fillDiskModeInodes
+		if opts.Size < 1 || opts.Size > 100 {
+			return opts, fmt.Errorf("inode usage must be between 1 and 100 percent, got %d", opts.Size)
+		}
 	default:
 		return opts, fmt.Errorf("invalid mode %s", request.Config["mode"])
 	}
@@ -189,6 +288,28 @@ func (a *fillDiskAction) Prepare(ctx context.Context, state *FillDiskActionState
 		return nil, err
 	}
 
+	hostMounts, err := mounts.ReadHostMountInfo()
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to read the mounts of the host.", err)
+	}
+	var mount *mounts.MountInfo
+	var warning string
+	resolved, err := hostfs.EvalSymlinks(opts.TempPath)
+	if err != nil {
+		err = fmt.Errorf("%s does not exist on the host: %w", opts.TempPath, err)
+	} else {
+		mount, warning, err = fillDiskMount(hostMounts, resolved)
+	}
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Cannot fill the disk of %s", opts.TempPath),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
 	initProcess, err := ociruntime.ReadLinuxProcessInfo(ctx, 1, specs.PIDNamespace)
 	if err != nil {
 		return nil, extension_kit.ToError("Failed to prepare fill disk settings.", err)
@@ -201,10 +322,47 @@ func (a *fillDiskAction) Prepare(ctx context.Context, state *FillDiskActionState
 	}
 	state.FillDiskOpts = opts
 	state.ExecutionId = request.ExecutionId
-	return nil, nil
+	state.MountPoint = mount.MountPoint
+	state.FsType = mount.FsType
+	state.Device = mount.Source
+
+	if warning == "" {
+		return nil, nil
+	}
+	return &action_kit_api.PrepareResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Warn),
+				Message: warning,
+			},
+		}),
+	}, nil
+}
+
+// fillDiskMount resolves the mount of the path. Memory backed filesystems are refused, as filling them fills the
+// memory instead of a disk. Overlays are only warned about, as they fill the filesystem of their upper directory.
+func fillDiskMount(hostMounts []mounts.MountInfo, path string) (*mounts.MountInfo, string, error) {
+	mount, err := mounts.FindMount(hostMounts, path)
+	if err != nil {
+		return nil, "", err
+	}
+	if mount.MemoryBacked() {
+		return nil, "", fmt.Errorf("%s is on the %s mounted at %s, which would fill the memory instead of a disk. Choose a path on a disk, e.g. /var/tmp", path, mount.FsType, mount.MountPoint)
+	}
+	if mount.ReadOnly() {
+		return nil, "", fmt.Errorf("%s is on the read-only filesystem mounted at %s", path, mount.MountPoint)
+	}
+	if mount.FsType == "overlay" {
+		return mount, fmt.Sprintf("%s is on the overlay mounted at %s, the filesystem of its upper directory will be filled", path, mount.MountPoint), nil
+	}
+	return mount, "", nil
 }
 
 func (a *fillDiskAction) diskfill(ctx context.Context, sidecar diskfill.SidecarOpts, opts diskfill.Opts) (diskfill.Diskfill, error) {
+	if opts.Mode == fillDiskModeInodes {
+		return inodefill.New(inodefill.Dir(opts.TempPath, sidecar.ExecutionId), opts.Size)
+	}
+
 	if config.Config.DisableRunc {
 		return diskfill.NewDiskfillProcess(ctx, opts)
 	}
@@ -230,6 +388,12 @@ func (a *fillDiskAction) Start(ctx context.Context, state *FillDiskActionState)
 			Message: fmt.Sprintf("Starting fill disk on host with args %s", diskFill.Args()),
 		},
 	}
+	if state.MountPoint != "" {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Filling the %s filesystem of %s mounted at %s", state.FsType, state.Device, state.MountPoint),
+		})
+	}
 
 	if diskFill.Noop() {
 		messages = append(messages, action_kit_api.Message{
@@ -244,11 +408,13 @@ func (a *fillDiskAction) Start(ctx context.Context, state *FillDiskActionState)
 }
 
 func (a *fillDiskAction) Status(_ context.Context, state *FillDiskActionState) (*action_kit_api.StatusResult, error) {
+	metrics := fillDiskMetrics(state, time.Now())
 	if _, err := a.fillDiskHostExited(state.ExecutionId); err == nil {
-		return &action_kit_api.StatusResult{Completed: false}, nil
+		return &action_kit_api.StatusResult{Completed: false, Metrics: &metrics}, nil
 	} else {
 		return &action_kit_api.StatusResult{
 			Completed: true,
+			Metrics:   &metrics,
 			Error: &action_kit_api.ActionKitError{
 				Status: extutil.Ptr(action_kit_api.Failed),
 				Title:  fmt.Sprintf("Failed to fill disk on host: %s", err.Error()),
@@ -257,21 +423,111 @@ func (a *fillDiskAction) Status(_ context.Context, state *FillDiskActionState) (
 	}
 }
 
+// fillDiskFile returns the path of the file written by the fill. Without runc the fill runs in the extension's own
+// filesystem, otherwise the path is bind mounted from the host.
+func fillDiskFile(opts diskfill.Opts) string {
+	file := filepath.Join(opts.TempPath, "disk-fill")
+	if config.Config.DisableRunc {
+		return file
+	}
+	return hostfs.Path(file)
+}
+
+func fillDiskMetrics(state *FillDiskActionState, now time.Time) []action_kit_api.Metric {
+	path := state.FillDiskOpts.TempPath
+	metrics := make([]action_kit_api.Metric, 0, 4)
+
+	usage, err := hostfs.Statfs(path)
+	if err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("Failed to read filesystem usage")
+		return metrics
+	}
+	fsUsage := func(usageType string, value float64) action_kit_api.Metric {
+		return action_kit_api.Metric{
+			Name: extutil.Ptr("fs_usage"),
+			Metric: map[string]string{
+				"usage_type": usageType,
+				"path":       path,
+			},
+			Value:     value,
+			Timestamp: now,
+		}
+	}
+
+	if state.FillDiskOpts.Mode == fillDiskModeInodes {
+		return append(metrics, fsUsage("Inodes Used", usage.InodesUsedPercent()))
+	}
+
+	metrics = append(metrics,
+		fsUsage("Used", usage.UsedPercent()),
+		fsUsage("Free", 100-usage.UsedPercent()),
+	)
+
+	var st unix.Stat_t
+	if err := unix.Stat(fillDiskFile(state.FillDiskOpts), &st); err == nil {
+		metrics = append(metrics, action_kit_api.Metric{
+			Name: extutil.Ptr("fill_disk_written"),
+			Metric: map[string]string{
+				"path": path,
+			},
+			// blocks are counted in 512 byte units, regardless of the block size of the filesystem
+			Value:     float64(st.Blocks*512) / bytesPerMegabyte,
+			Timestamp: now,
+		})
+	}
+	return metrics
+}
+
 func (a *fillDiskAction) Stop(_ context.Context, state *FillDiskActionState) (*action_kit_api.StopResult, error) {
-	if err := a.stopFillDiskHost(state.ExecutionId); err != nil {
+	before, usageErr := hostfs.Statfs(state.FillDiskOpts.TempPath)
+
+	if err := a.stopFillDiskHost(state.ExecutionId); err != nil && state.FillDiskOpts.Mode == fillDiskModeInodes {
+		// the extension may have been restarted, remove the files left behind
+		if err := inodefill.Remove(inodefill.Dir(state.FillDiskOpts.TempPath, state.ExecutionId)); err != nil {
+			return nil, extension_kit.ToError("Failed to stop fill disk on host", err)
+		}
+	} else if err != nil {
 		return nil, extension_kit.ToError("Failed to stop fill disk on host", err)
 	}
 
-	return &action_kit_api.StopResult{
-		Messages: &[]action_kit_api.Message{
-			{
-				Level:   extutil.Ptr(action_kit_api.Info),
-				Message: "Canceled fill disk on host",
-			},
+	messages := []action_kit_api.Message{
+		{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: "Canceled fill disk on host",
 		},
+	}
+
+	after, err := hostfs.Statfs(state.FillDiskOpts.TempPath)
+	if err = errors.Join(usageErr, err); err != nil {
+		log.Warn().Err(err).Msg("Failed to read filesystem usage, can't report reclaimed space")
+	} else {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: reclaimedMessage(state.FillDiskOpts, before, after),
+		})
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: &messages,
 	}, nil
 }
 
+func reclaimedMessage(opts diskfill.Opts, before, after hostfs.Usage) string {
+	if opts.Mode == fillDiskModeInodes {
+		var freed uint64
+		if after.InodesFree > before.InodesFree {
+			freed = after.InodesFree - before.InodesFree
+		}
+		return fmt.Sprintf("Freed %d inodes on the filesystem of %s, %.1f%% of inodes are used now", freed, opts.TempPath, after.InodesUsedPercent())
+	}
+
+	var reclaimed uint64
+	if after.Free > before.Free {
+		reclaimed = after.Free - before.Free
+	}
+	return fmt.Sprintf("Reclaimed %d MiB on the filesystem of %s, %.1f%% are used now", reclaimed/bytesPerMegabyte, opts.TempPath, after.UsedPercent())
+}
+
 func (a *fillDiskAction) stopFillDiskHost(executionId uuid.UUID) error {
 	s, ok := a.diskfills.LoadAndDelete(executionId)
 	if !ok {
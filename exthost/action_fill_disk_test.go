@@ -0,0 +1,111 @@
+package exthost
+
+import (
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_commons/diskfill"
+	"github.com/steadybit/extension-host/config"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-host/exthost/mounts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_fillDiskOpts(t *testing.T) {
+	request := func(mode string, size float64) action_kit_api.PrepareActionRequestBody {
+		return action_kit_api.PrepareActionRequestBody{
+			Config: map[string]interface{}{
+				"mode":      mode,
+				"size":      size,
+				"path":      "/var/lib",
+				"method":    string(diskfill.AtOnce),
+				"blocksize": float64(5),
+			},
+		}
+	}
+
+	opts, err := fillDiskOpts(request(string(fillDiskModeInodes), 95))
+	require.NoError(t, err)
+	assert.Equal(t, diskfill.Opts{Mode: fillDiskModeInodes, Size: 95, TempPath: "/var/lib", Method: diskfill.AtOnce, BlockSize: 5}, opts)
+
+	_, err = fillDiskOpts(request(string(fillDiskModeInodes), 101))
+	assert.EqualError(t, err, "inode usage must be between 1 and 100 percent, got 101")
+
+	_, err = fillDiskOpts(request("UNKNOWN", 10))
+	assert.EqualError(t, err, "invalid mode UNKNOWN")
+}
+
+func Test_fillDiskMetrics(t *testing.T) {
+	oldRootPath := hostfs.RootPath
+	hostfs.RootPath = t.TempDir()
+	t.Cleanup(func() {
+		hostfs.RootPath = oldRootPath
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(hostfs.RootPath, "tmp"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostfs.RootPath, "tmp", "disk-fill"), make([]byte, 2*bytesPerMegabyte), 0644))
+
+	state := &FillDiskActionState{FillDiskOpts: diskfill.Opts{Mode: diskfill.Percentage, TempPath: "/tmp"}}
+	metrics := fillDiskMetrics(state, time.Now())
+	require.Len(t, metrics, 3)
+	assert.Equal(t, "Used", metrics[0].Metric["usage_type"])
+	assert.Equal(t, "Free", metrics[1].Metric["usage_type"])
+	assert.InDelta(t, 100, metrics[0].Value+metrics[1].Value, 0.001)
+	assert.Equal(t, "fill_disk_written", *metrics[2].Name)
+	assert.Equal(t, float64(2), metrics[2].Value)
+
+	state.FillDiskOpts.Mode = fillDiskModeInodes
+	metrics = fillDiskMetrics(state, time.Now())
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "Inodes Used", metrics[0].Metric["usage_type"])
+}
+
+func Test_fillDiskFile(t *testing.T) {
+	oldRootPath, oldDisableRunc := hostfs.RootPath, config.Config.DisableRunc
+	t.Cleanup(func() {
+		hostfs.RootPath, config.Config.DisableRunc = oldRootPath, oldDisableRunc
+	})
+	hostfs.RootPath = "/host"
+	opts := diskfill.Opts{TempPath: "/var/lib"}
+
+	config.Config.DisableRunc = false
+	assert.Equal(t, "/host/var/lib/disk-fill", fillDiskFile(opts))
+
+	config.Config.DisableRunc = true
+	assert.Equal(t, "/var/lib/disk-fill", fillDiskFile(opts))
+}
+
+func Test_reclaimedMessage(t *testing.T) {
+	before := hostfs.Usage{Total: 1000 * bytesPerMegabyte, Free: 100 * bytesPerMegabyte, Inodes: 1000, InodesFree: 10}
+	after := hostfs.Usage{Total: 1000 * bytesPerMegabyte, Free: 600 * bytesPerMegabyte, Inodes: 1000, InodesFree: 900}
+
+	assert.Equal(t, "Reclaimed 500 MiB on the filesystem of /tmp, 40.0% are used now", reclaimedMessage(diskfill.Opts{Mode: diskfill.Percentage, TempPath: "/tmp"}, before, after))
+	assert.Equal(t, "Freed 890 inodes on the filesystem of /tmp, 10.0% of inodes are used now", reclaimedMessage(diskfill.Opts{Mode: fillDiskModeInodes, TempPath: "/tmp"}, before, after))
+}
+
+func Test_fillDiskMount(t *testing.T) {
+	hostMounts := []mounts.MountInfo{
+		{MountPoint: "/", Root: "/", FsType: "ext4", Source: "/dev/sda1", Options: []string{"rw"}},
+		{MountPoint: "/tmp", Root: "/", FsType: "tmpfs", Source: "tmpfs", Options: []string{"rw"}},
+		{MountPoint: "/data", Root: "/", FsType: "overlay", Source: "overlay", Options: []string{"rw"}},
+		{MountPoint: "/mnt/iso", Root: "/", FsType: "iso9660", Source: "/dev/sr0", Options: []string{"ro"}},
+	}
+
+	mount, warning, err := fillDiskMount(hostMounts, "/var/tmp")
+	require.NoError(t, err)
+	assert.Equal(t, "/", mount.MountPoint)
+	assert.Empty(t, warning)
+
+	_, _, err = fillDiskMount(hostMounts, "/tmp/data")
+	assert.ErrorContains(t, err, "would fill the memory instead of a disk")
+
+	_, _, err = fillDiskMount(hostMounts, "/mnt/iso")
+	assert.ErrorContains(t, err, "read-only filesystem")
+
+	mount, warning, err = fillDiskMount(hostMounts, "/data/files")
+	require.NoError(t, err)
+	assert.Equal(t, "/data", mount.MountPoint)
+	assert.Contains(t, warning, "overlay mounted at /data")
+}
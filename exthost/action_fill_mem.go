@@ -8,19 +8,34 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_commons/memfill"
 	"github.com/steadybit/action-kit/go/action_kit_commons/ociruntime"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/extension-host/config"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-host/exthost/kmsg"
+	"github.com/steadybit/extension-host/exthost/meminfo"
+	"github.com/steadybit/extension-host/exthost/pagecache"
+	stopprocess "github.com/steadybit/extension-host/exthost/process"
 	extension_kit "github.com/steadybit/extension-kit"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
 	"golang.org/x/sync/syncmap"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+const (
+	memoryTypeAnonymous = "ANONYMOUS"
+	memoryTypePageCache = "PAGE_CACHE"
+	memoryTypeForceSwap = "FORCE_SWAP"
+)
+
 type fillMemoryAction struct {
 	ociRuntime ociruntime.OciRuntime
 	memfills   syncmap.Map
@@ -29,8 +44,13 @@ type fillMemoryAction struct {
 type FillMemoryActionState struct {
 	ExecutionId     uuid.UUID
 	TargetProcess   ociruntime.LinuxProcessInfo
+	MemoryType      string
 	FillMemoryOpts  memfill.Opts
+	PageCacheOpts   pagecache.Opts
 	IgnoreExitCodes []int
+	MemfillPid      int
+	KmsgSeq         uint64
+	ReportOomKills  bool
 }
 
 // Make sure fillMemoryAction implements all required interfaces
@@ -63,6 +83,77 @@ func (a *fillMemoryAction) Describe() action_kit_api.ActionDescription {
 		Category:    extutil.Ptr("Resource"),
 		Kind:        action_kit_api.Attack,
 		TimeControl: action_kit_api.TimeControlExternal,
+		Widgets: extutil.Ptr([]action_kit_api.Widget{
+			action_kit_api.LineChartWidget{
+				Type:  action_kit_api.ComSteadybitWidgetLineChart,
+				Title: "Memory Usage",
+				Identity: action_kit_api.LineChartWidgetIdentityConfig{
+					MetricName: "memory_usage",
+					From:       "memory_type",
+					Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeWidgetPerValue,
+				},
+				Grouping: extutil.Ptr(action_kit_api.LineChartWidgetGroupingConfig{
+					ShowSummary: extutil.Ptr(true),
+					Groups: []action_kit_api.LineChartWidgetGroup{
+						{
+							Title: "Used",
+							Color: "danger",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "memory_type",
+								Value: "Used",
+							},
+						},
+						{
+							Title: "Allocated",
+							Color: "muted",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "memory_type",
+								Value: "Allocated",
+							},
+						},
+						{
+							Title: "RSS",
+							Color: "info",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "memory_type",
+								Value: "RSS",
+							},
+						},
+						{
+							Title: "Cache",
+							Color: "success",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "memory_type",
+								Value: "Cache",
+							},
+						},
+						{
+							Title: "Swap",
+							Color: "warn",
+							Matcher: action_kit_api.LineChartWidgetGroupMatcherKeyEqualsValue{
+								Type:  action_kit_api.ComSteadybitWidgetLineChartGroupMatcherKeyEqualsValue,
+								Key:   "memory_type",
+								Value: "Swap",
+							},
+						},
+					},
+				}),
+				Tooltip: extutil.Ptr(action_kit_api.LineChartWidgetTooltipConfig{
+					MetricValueTitle: extutil.Ptr("Usage"),
+					MetricValueUnit:  extutil.Ptr("MiB"),
+					AdditionalContent: []action_kit_api.LineChartWidgetTooltipContent{
+						{
+							From:  "memory_type",
+							Title: "Type",
+						},
+					},
+				}),
+			},
+		}),
 		Parameters: []action_kit_api.ActionParameter{
 			{
 				Name:         "duration",
@@ -73,6 +164,29 @@ func (a *fillMemoryAction) Describe() action_kit_api.ActionDescription {
 				Required:     extutil.Ptr(true),
 				Order:        extutil.Ptr(1),
 			},
+			{
+				Name:         "memoryType",
+				Label:        "Memory Type",
+				Description:  extutil.Ptr("*Anonymous:* Allocate process memory.\n\n*Page Cache:* Write and repeatedly read a file, so its pages are held in the page cache. The size is always the amount to fill.\n\n*Force Swap:* Allocate all available memory plus the given size, pushing memory into swap. The size is a percentage of the swap space or Megabytes. Requires active swap."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(memoryTypeAnonymous),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(2),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Anonymous",
+						Value: memoryTypeAnonymous,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Page Cache",
+						Value: memoryTypePageCache,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Force Swap",
+						Value: memoryTypeForceSwap,
+					},
+				}),
+			},
 			{
 				Name:         "mode",
 				Label:        "Mode",
@@ -80,7 +194,7 @@ func (a *fillMemoryAction) Describe() action_kit_api.ActionDescription {
 				Type:         action_kit_api.ActionParameterTypeString,
 				DefaultValue: extutil.Ptr(string(memfill.ModeUsage)),
 				Required:     extutil.Ptr(true),
-				Order:        extutil.Ptr(2),
+				Order:        extutil.Ptr(3),
 				Options: extutil.Ptr([]action_kit_api.ParameterOption{
 					action_kit_api.ExplicitParameterOption{
 						Label: "Fill and meet specified usage",
@@ -99,7 +213,7 @@ func (a *fillMemoryAction) Describe() action_kit_api.ActionDescription {
 				Type:         action_kit_api.ActionParameterTypeInteger,
 				DefaultValue: extutil.Ptr("80"),
 				Required:     extutil.Ptr(true),
-				Order:        extutil.Ptr(3),
+				Order:        extutil.Ptr(4),
 			},
 			{
 				Name:         "unit",
@@ -108,7 +222,7 @@ func (a *fillMemoryAction) Describe() action_kit_api.ActionDescription {
 				Type:         action_kit_api.ActionParameterTypeString,
 				DefaultValue: extutil.Ptr(string(memfill.UnitPercent)),
 				Required:     extutil.Ptr(true),
-				Order:        extutil.Ptr(4),
+				Order:        extutil.Ptr(5),
 				Options: extutil.Ptr([]action_kit_api.ParameterOption{
 					action_kit_api.ExplicitParameterOption{
 						Label: "Megabytes",
@@ -127,7 +241,17 @@ func (a *fillMemoryAction) Describe() action_kit_api.ActionDescription {
 				Type:         action_kit_api.ActionParameterTypeBoolean,
 				DefaultValue: extutil.Ptr("false"),
 				Required:     extutil.Ptr(true),
-				Order:        extutil.Ptr(5),
+				Order:        extutil.Ptr(6),
+			},
+			{
+				Name:         "pageCachePath",
+				Label:        "Page Cache Directory",
+				Description:  extutil.Ptr("Directory on the host to write the file to when using memory type 'Page Cache'. Must be on a disk-backed filesystem with enough free space."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr("/var/tmp"),
+				Required:     extutil.Ptr(true),
+				Advanced:     extutil.Ptr(true),
+				Order:        extutil.Ptr(7),
 			},
 		},
 	}
@@ -144,6 +268,54 @@ func fillMemoryOpts(request action_kit_api.PrepareActionRequestBody) (memfill.Op
 	return opts, nil
 }
 
+// forceSwapOpts sizes memfill to allocate all available memory plus the requested amount of swap.
+func forceSwapOpts(opts memfill.Opts, info meminfo.Meminfo) (memfill.Opts, error) {
+	if info.SwapTotal == 0 {
+		return opts, errors.New("no swap is active on the host")
+	}
+
+	swap := uint64(opts.Size) * bytesPerMegabyte
+	if opts.Unit == memfill.UnitPercent {
+		swap = info.SwapTotal * uint64(opts.Size) / 100
+	}
+	if swap > info.SwapFree {
+		return opts, fmt.Errorf("requested %d MiB of swap, but only %d MiB are free", swap/bytesPerMegabyte, info.SwapFree/bytesPerMegabyte)
+	}
+
+	opts.Mode = memfill.ModeAbsolute
+	opts.Unit = memfill.UnitMegabyte
+	opts.Size = int((info.MemAvailable + swap) / bytesPerMegabyte)
+	return opts, nil
+}
+
+// pageCacheSize returns the size of the file to hold in the page cache.
+func pageCacheSize(opts memfill.Opts, info meminfo.Meminfo) uint64 {
+	if opts.Unit == memfill.UnitPercent {
+		return info.MemTotal * uint64(opts.Size) / 100
+	}
+	return uint64(opts.Size) * bytesPerMegabyte
+}
+
+func memoryMetrics(info meminfo.Meminfo, now time.Time) []action_kit_api.Metric {
+	return []action_kit_api.Metric{
+		memoryMetric("Used", info.Used(), now),
+		memoryMetric("RSS", info.AnonPages, now),
+		memoryMetric("Cache", info.Cached, now),
+		memoryMetric("Swap", info.SwapUsed(), now),
+	}
+}
+
+func memoryMetric(memoryType string, value uint64, now time.Time) action_kit_api.Metric {
+	return action_kit_api.Metric{
+		Name: extutil.Ptr("memory_usage"),
+		Metric: map[string]string{
+			"memory_type": memoryType,
+		},
+		Value:     float64(value) / bytesPerMegabyte,
+		Timestamp: now,
+	}
+}
+
 func (a *fillMemoryAction) Prepare(ctx context.Context, state *FillMemoryActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
 		return nil, err
@@ -159,7 +331,52 @@ func (a *fillMemoryAction) Prepare(ctx context.Context, state *FillMemoryActionS
 		return nil, extension_kit.ToError("Failed to prepare fill memory settings.", err)
 	}
 
+	memoryType := extutil.ToString(request.Config["memoryType"])
+	if memoryType == "" {
+		memoryType = memoryTypeAnonymous
+	}
+
+	switch memoryType {
+	case memoryTypeAnonymous:
+	case memoryTypePageCache, memoryTypeForceSwap:
+		info, err := meminfo.Read()
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to read the memory of the host.", err)
+		}
+
+		if memoryType == memoryTypeForceSwap {
+			if opts, err = forceSwapOpts(opts, info); err != nil {
+				return &action_kit_api.PrepareResult{
+					Error: extutil.Ptr(action_kit_api.ActionKitError{
+						Title:  "Cannot force the host to swap",
+						Status: extutil.Ptr(action_kit_api.Errored),
+						Detail: extutil.Ptr(err.Error()),
+					}),
+				}, nil
+			}
+			break
+		}
+
+		dir := extutil.ToString(request.Config["pageCachePath"])
+		if !filepath.IsAbs(dir) {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  fmt.Sprintf("Page cache directory must be an absolute path, got '%s'", dir),
+					Status: extutil.Ptr(action_kit_api.Errored),
+				}),
+			}, nil
+		}
+		state.PageCacheOpts = pagecache.Opts{
+			File:     filepath.Join(dir, fmt.Sprintf("steadybit-page-cache-%s", request.ExecutionId)),
+			Size:     pageCacheSize(opts, info),
+			Duration: opts.Duration,
+		}
+	default:
+		return nil, fmt.Errorf("invalid memory type %s", memoryType)
+	}
+
 	state.TargetProcess = initProcess
+	state.MemoryType = memoryType
 	state.FillMemoryOpts = opts
 	state.ExecutionId = request.ExecutionId
 
@@ -169,16 +386,32 @@ func (a *fillMemoryAction) Prepare(ctx context.Context, state *FillMemoryActionS
 	return nil, nil
 }
 
-func (a *fillMemoryAction) memfill(targetProcess ociruntime.LinuxProcessInfo, opts memfill.Opts) (memfill.Memfill, error) {
+func (a *fillMemoryAction) memfill(state *FillMemoryActionState) (memfill.Memfill, error) {
+	if state.MemoryType == memoryTypePageCache {
+		return pagecache.New(state.TargetProcess, state.PageCacheOpts), nil
+	}
+
 	if config.Config.DisableRunc {
-		return memfill.NewMemfillProcess(targetProcess, opts)
+		return memfill.NewMemfillProcess(state.TargetProcess, state.FillMemoryOpts)
 	}
 
-	return memfill.NewMemfillProcess(targetProcess, opts)
+	return memfill.NewMemfillProcess(state.TargetProcess, state.FillMemoryOpts)
 }
 
 func (a *fillMemoryAction) Start(_ context.Context, state *FillMemoryActionState) (*action_kit_api.StartResult, error) {
-	memFill, err := a.memfill(state.TargetProcess, state.FillMemoryOpts)
+	messages := make([]action_kit_api.Message, 0)
+	if seq, err := kmsg.LastSeq(); err != nil {
+		log.Warn().Err(err).Msg("Failed to read the kernel log, OOM kills won't be reported")
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: fmt.Sprintf("OOM kills can't be reported, failed to read the kernel log: %s", err),
+		})
+	} else {
+		state.KmsgSeq = seq
+		state.ReportOomKills = true
+	}
+
+	memFill, err := a.memfill(state)
 	if err != nil {
 		return nil, extension_kit.ToError("Failed to prepare fill memory on host", err)
 	}
@@ -189,31 +422,41 @@ func (a *fillMemoryAction) Start(_ context.Context, state *FillMemoryActionState
 		return nil, extension_kit.ToError("Failed to fill memory on host", err)
 	}
 
+	messages = append(messages, action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Starting fill memory on host with args %s", memFill.Args()),
+	})
 	return &action_kit_api.StartResult{
-		Messages: extutil.Ptr([]action_kit_api.Message{
-			{
-				Level:   extutil.Ptr(action_kit_api.Info),
-				Message: fmt.Sprintf("Starting fill memory on host with args %s", memFill.Args()),
-			},
-		}),
+		Messages: &messages,
 	}, nil
 }
 
 func (a *fillMemoryAction) Status(_ context.Context, state *FillMemoryActionState) (*action_kit_api.StatusResult, error) {
+	now := time.Now()
+	metrics := make([]action_kit_api.Metric, 0, 5)
+	if info, err := meminfo.Read(); err != nil {
+		log.Warn().Err(err).Msg("Failed to read memory usage of the host")
+	} else {
+		metrics = append(metrics, memoryMetrics(info, now)...)
+	}
+	if allocated, ok := a.allocatedBytes(state); ok {
+		metrics = append(metrics, memoryMetric("Allocated", allocated, now))
+	}
+	messages := oomKillMessages(state)
+
 	exited, err := a.fillMemoryExited(state.ExecutionId)
 	if !exited {
-		return &action_kit_api.StatusResult{Completed: false}, nil
+		return &action_kit_api.StatusResult{Completed: false, Metrics: &metrics, Messages: &messages}, nil
 	}
 
 	if err == nil {
 		return &action_kit_api.StatusResult{
 			Completed: true,
-			Messages: &[]action_kit_api.Message{
-				{
-					Level:   extutil.Ptr(action_kit_api.Info),
-					Message: "fill memory on host stopped",
-				},
-			},
+			Metrics:   &metrics,
+			Messages: extutil.Ptr(append(messages, action_kit_api.Message{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: "fill memory on host stopped",
+			})),
 		}, nil
 	}
 
@@ -229,12 +472,11 @@ func (a *fillMemoryAction) Status(_ context.Context, state *FillMemoryActionStat
 			if exitCode == ignore {
 				return &action_kit_api.StatusResult{
 					Completed: true,
-					Messages: &[]action_kit_api.Message{
-						{
-							Level:   extutil.Ptr(action_kit_api.Warn),
-							Message: fmt.Sprintf("memfill exited unexpectedly: %s", errMessage),
-						},
-					},
+					Metrics:   &metrics,
+					Messages: extutil.Ptr(append(messages, action_kit_api.Message{
+						Level:   extutil.Ptr(action_kit_api.Warn),
+						Message: fmt.Sprintf("memfill exited unexpectedly: %s", errMessage),
+					})),
 				}, nil
 			}
 		}
@@ -242,6 +484,8 @@ func (a *fillMemoryAction) Status(_ context.Context, state *FillMemoryActionStat
 
 	return &action_kit_api.StatusResult{
 		Completed: true,
+		Metrics:   &metrics,
+		Messages:  &messages,
 		Error: &action_kit_api.ActionKitError{
 			Status: extutil.Ptr(action_kit_api.Failed),
 			Title:  fmt.Sprintf("Failed to fill memory on host: %s", errMessage),
@@ -249,7 +493,79 @@ func (a *fillMemoryAction) Status(_ context.Context, state *FillMemoryActionStat
 	}, nil
 }
 
-func (a *fillMemoryAction) Stop(_ context.Context, state *FillMemoryActionState) (*action_kit_api.StopResult, error) {
+// allocatedBytes returns the memory held by the running memfill process or the size of the page cache file.
+func (a *fillMemoryAction) allocatedBytes(state *FillMemoryActionState) (uint64, bool) {
+	s, ok := a.memfills.Load(state.ExecutionId)
+	if !ok {
+		return 0, false
+	}
+
+	if state.MemoryType == memoryTypePageCache {
+		fi, err := os.Stat(hostfs.Path(state.PageCacheOpts.File))
+		if err != nil {
+			return 0, false
+		}
+		return uint64(fi.Size()), true
+	}
+
+	if state.MemfillPid == 0 {
+		state.MemfillPid = findMemfillPid(s.(memfill.Memfill).Args())
+		if state.MemfillPid == 0 {
+			return 0, false
+		}
+	}
+	usage, err := meminfo.ProcessUsage(state.MemfillPid)
+	if err != nil {
+		log.Debug().Err(err).Int("pid", state.MemfillPid).Msg("Failed to read memory usage of memfill")
+		return 0, false
+	}
+	return usage, true
+}
+
+func findMemfillPid(args []string) int {
+	for _, pid := range stopprocess.FindProcessIds("memfill") {
+		cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil {
+			continue
+		}
+		if strings.TrimRight(string(cmdline), "\x00") == strings.Join(args, "\x00") {
+			return pid
+		}
+	}
+	return 0
+}
+
+// oomKillMessages reports the processes killed by the OOM killer since the last status.
+func oomKillMessages(state *FillMemoryActionState) []action_kit_api.Message {
+	messages := make([]action_kit_api.Message, 0)
+	if !state.ReportOomKills {
+		return messages
+	}
+
+	records, err := kmsg.Read()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read the kernel log")
+		return messages
+	}
+
+	var recent []kmsg.Record
+	for _, r := range records {
+		if r.Seq > state.KmsgSeq {
+			recent = append(recent, r)
+			state.KmsgSeq = r.Seq
+		}
+	}
+
+	for _, kill := range kmsg.OomKills(recent) {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: fmt.Sprintf("OOM killer killed %s", kill),
+		})
+	}
+	return messages
+}
+
+func (a *fillMemoryAction) Stop(ctx context.Context, state *FillMemoryActionState) (*action_kit_api.StopResult, error) {
 	messages := make([]action_kit_api.Message, 0)
 
 	if a.stopFillMemoryHost(state.ExecutionId) {
@@ -257,6 +573,10 @@ func (a *fillMemoryAction) Stop(_ context.Context, state *FillMemoryActionState)
 			Level:   extutil.Ptr(action_kit_api.Info),
 			Message: "Canceled fill memory on host",
 		})
+	} else if state.MemoryType == memoryTypePageCache && state.PageCacheOpts.File != "" {
+		if err := pagecache.Cleanup(ctx, state.PageCacheOpts.File); err != nil {
+			log.Warn().Err(err).Msg("Failed to remove page cache file")
+		}
 	}
 
 	return &action_kit_api.StopResult{
@@ -0,0 +1,70 @@
+package exthost
+
+import (
+	"github.com/steadybit/action-kit/go/action_kit_commons/memfill"
+	"github.com/steadybit/extension-host/exthost/meminfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_forceSwapOpts(t *testing.T) {
+	info := meminfo.Meminfo{
+		MemTotal:     8192 * bytesPerMegabyte,
+		MemAvailable: 4096 * bytesPerMegabyte,
+		SwapTotal:    2048 * bytesPerMegabyte,
+		SwapFree:     1024 * bytesPerMegabyte,
+	}
+
+	tests := []struct {
+		name        string
+		opts        memfill.Opts
+		info        meminfo.Meminfo
+		wantedSize  int
+		wantedError string
+	}{
+		{
+			name:       "percentage of swap",
+			opts:       memfill.Opts{Size: 25, Unit: memfill.UnitPercent, Mode: memfill.ModeUsage},
+			info:       info,
+			wantedSize: 4096 + 512,
+		},
+		{
+			name:       "megabytes of swap",
+			opts:       memfill.Opts{Size: 100, Unit: memfill.UnitMegabyte, Mode: memfill.ModeUsage},
+			info:       info,
+			wantedSize: 4096 + 100,
+		},
+		{
+			name:        "more than free swap",
+			opts:        memfill.Opts{Size: 80, Unit: memfill.UnitPercent},
+			info:        info,
+			wantedError: "requested 1638 MiB of swap, but only 1024 MiB are free",
+		},
+		{
+			name:        "no swap",
+			opts:        memfill.Opts{Size: 10, Unit: memfill.UnitPercent},
+			info:        meminfo.Meminfo{MemTotal: 1024, MemAvailable: 512},
+			wantedError: "no swap is active on the host",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := forceSwapOpts(tt.opts, tt.info)
+			if tt.wantedError != "" {
+				assert.EqualError(t, err, tt.wantedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, memfill.ModeAbsolute, opts.Mode)
+			assert.Equal(t, memfill.UnitMegabyte, opts.Unit)
+			assert.Equal(t, tt.wantedSize, opts.Size)
+		})
+	}
+}
+
+func Test_pageCacheSize(t *testing.T) {
+	info := meminfo.Meminfo{MemTotal: 1000 * bytesPerMegabyte}
+	assert.Equal(t, uint64(500*bytesPerMegabyte), pageCacheSize(memfill.Opts{Size: 50, Unit: memfill.UnitPercent}, info))
+	assert.Equal(t, uint64(64*bytesPerMegabyte), pageCacheSize(memfill.Opts{Size: 64, Unit: memfill.UnitMegabyte}, info))
+}
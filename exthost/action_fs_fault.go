@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_commons/ociruntime"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/fsfault"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"golang.org/x/sync/syncmap"
+)
+
+const fsFaultErrorNone = "NONE"
+
+type fsFaultAction struct {
+	processes syncmap.Map
+}
+
+type FsFaultActionState struct {
+	ExecutionId   uuid.UUID
+	TargetProcess ociruntime.LinuxProcessInfo
+	Path          string
+	Config        fsfault.Config
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[FsFaultActionState]           = (*fsFaultAction)(nil)
+	_ action_kit_sdk.ActionWithStop[FsFaultActionState]   = (*fsFaultAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[FsFaultActionState] = (*fsFaultAction)(nil)
+)
+
+func NewFsFaultAction() action_kit_sdk.Action[FsFaultActionState] {
+	return &fsFaultAction{}
+}
+
+func (a *fsFaultAction) NewEmptyState() FsFaultActionState {
+	return FsFaultActionState{}
+}
+
+func (a *fsFaultAction) Describe() action_kit_api.ActionDescription {
+	errorOptions := []action_kit_api.ParameterOption{
+		action_kit_api.ExplicitParameterOption{Label: "None (latency only)", Value: fsFaultErrorNone},
+	}
+	errors := make([]string, 0, len(fsfault.Errors))
+	for name := range fsfault.Errors {
+		errors = append(errors, name)
+	}
+	slices.Sort(errors)
+	for _, name := range errors {
+		errorOptions = append(errorOptions, action_kit_api.ExplicitParameterOption{Label: name, Value: name})
+	}
+
+	operationOptions := make([]action_kit_api.ParameterOption, 0, len(fsfault.Operations))
+	for _, op := range fsfault.Operations {
+		operationOptions = append(operationOptions, action_kit_api.ExplicitParameterOption{Label: op, Value: op})
+	}
+
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.fs-fault", BaseActionID),
+		Label:       "Filesystem Fault",
+		Description: "Injects errors or latency into the file operations below a directory of the host for the given duration, using a FUSE filesystem mounted over the directory.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(stressIOIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("Resource"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the faults be injected?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:        "path",
+				Label:       "Directory",
+				Description: extutil.Ptr("Directory on the host, all files below are affected. Files opened before the attack are not affected."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(true),
+				Order:       extutil.Ptr(2),
+			},
+			{
+				Name:         "error",
+				Label:        "Error",
+				Description:  extutil.Ptr("The error returned by the affected operations."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr("EIO"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(3),
+				Options:      extutil.Ptr(errorOptions),
+			},
+			{
+				Name:         "probability",
+				Label:        "Probability (%)",
+				Description:  extutil.Ptr("Probability of an operation to be affected."),
+				Type:         action_kit_api.ActionParameterTypePercentage,
+				DefaultValue: extutil.Ptr("100"),
+				Required:     extutil.Ptr(true),
+				MinValue:     extutil.Ptr(1),
+				MaxValue:     extutil.Ptr(100),
+				Order:        extutil.Ptr(4),
+			},
+			{
+				Name:         "latency",
+				Label:        "Latency",
+				Description:  extutil.Ptr("Latency added to the affected operations before they return."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("0s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(5),
+			},
+			{
+				Name:        "operations",
+				Label:       "Operations",
+				Description: extutil.Ptr("The file operations to affect. All if none specified."),
+				Type:        action_kit_api.ActionParameterTypeStringArray,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(6),
+				Options:     extutil.Ptr(operationOptions),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func fsFaultConfig(request action_kit_api.PrepareActionRequestBody) (fsfault.Config, error) {
+	c := fsfault.Config{
+		Error:       extutil.ToString(request.Config["error"]),
+		Probability: extutil.ToInt(request.Config["probability"]),
+		Latency:     time.Duration(extutil.ToInt64(request.Config["latency"])) * time.Millisecond,
+	}
+	if c.Error == fsFaultErrorNone {
+		c.Error = ""
+	}
+	for _, op := range extutil.ToStringArray(request.Config["operations"]) {
+		if op = strings.TrimSpace(op); op != "" {
+			c.Operations = append(c.Operations, op)
+		}
+	}
+	return c, c.Validate()
+}
+
+func (a *fsFaultAction) Prepare(ctx context.Context, state *FsFaultActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	config, err := fsFaultConfig(request)
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Invalid filesystem fault",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	path := filepath.Clean(extutil.ToString(request.Config["path"]))
+	var refusal error
+	if !filepath.IsAbs(path) {
+		refusal = fmt.Errorf("path must be absolute, got '%s'", path)
+	} else if fi, err := os.Stat(hostfs.Path(path)); err != nil || !fi.IsDir() {
+		refusal = fmt.Errorf("%s is not a directory on the host", path)
+	} else if mounted, err := fsfault.IsMounted(path); err != nil {
+		return nil, err
+	} else if mounted {
+		refusal = fmt.Errorf("faults are already injected into %s", path)
+	} else {
+		refusal = checkBindAllowed(path)
+	}
+	if refusal != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Cannot inject faults into %s", path),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(refusal.Error()),
+			}),
+		}, nil
+	}
+
+	initProcess, err := ociruntime.ReadLinuxProcessInfo(ctx, 1, specs.PIDNamespace)
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to prepare filesystem fault settings.", err)
+	}
+
+	state.ExecutionId = request.ExecutionId
+	state.TargetProcess = initProcess
+	state.Path = path
+	state.Config = config
+	return nil, nil
+}
+
+func (a *fsFaultAction) Start(_ context.Context, state *FsFaultActionState) (*action_kit_api.StartResult, error) {
+	process := fsfault.NewProcess(state.TargetProcess, state.Path, state.Config)
+	if err := process.Start(); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to inject faults into %s", state.Path), err)
+	}
+	a.processes.Store(state.ExecutionId, process)
+
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Injecting faults into %s with %s", state.Path, strings.Join(state.Config.Args(), " ")),
+			},
+		}),
+	}, nil
+}
+
+func (a *fsFaultAction) Status(_ context.Context, state *FsFaultActionState) (*action_kit_api.StatusResult, error) {
+	s, ok := a.processes.Load(state.ExecutionId)
+	if !ok {
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+
+	exited, err := s.(*fsfault.Process).Exited()
+	if !exited {
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+	if err == nil {
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+	return &action_kit_api.StatusResult{
+		Completed: true,
+		Error: &action_kit_api.ActionKitError{
+			Status: extutil.Ptr(action_kit_api.Failed),
+			Title:  fmt.Sprintf("Filesystem fault injection into %s failed: %s", state.Path, err.Error()),
+		},
+	}, nil
+}
+
+func (a *fsFaultAction) Stop(ctx context.Context, state *FsFaultActionState) (*action_kit_api.StopResult, error) {
+	if state.Path == "" {
+		return nil, nil
+	}
+
+	var err error
+	if s, ok := a.processes.LoadAndDelete(state.ExecutionId); ok {
+		err = s.(*fsfault.Process).Stop()
+	} else {
+		log.Debug().Str("path", state.Path).Msg("No fault injection process found, unmounting leftovers")
+		err = fsfault.Unmount(ctx, state.Path)
+	}
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to stop injecting faults into %s", state.Path), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Stopped injecting faults into %s", state.Path),
+			},
+		}),
+	}, nil
+}
@@ -0,0 +1,51 @@
+package exthost
+
+import (
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-host/exthost/fsfault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_fsFaultConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    fsfault.Config
+		wantErr string
+	}{
+		{
+			name:   "error on all operations",
+			config: map[string]interface{}{"error": "EIO", "probability": 50, "latency": 0},
+			want:   fsfault.Config{Error: "EIO", Probability: 50},
+		},
+		{
+			name:   "latency only on selected operations",
+			config: map[string]interface{}{"error": fsFaultErrorNone, "probability": 100, "latency": 250, "operations": []interface{}{"read", " write"}},
+			want:   fsfault.Config{Probability: 100, Latency: 250 * time.Millisecond, Operations: []string{"read", "write"}},
+		},
+		{
+			name:    "neither error nor latency",
+			config:  map[string]interface{}{"error": fsFaultErrorNone, "probability": 100, "latency": 0},
+			wantErr: "either an error or a latency is required",
+		},
+		{
+			name:    "unknown operation",
+			config:  map[string]interface{}{"error": "EIO", "probability": 100, "operations": []interface{}{"chmod"}},
+			wantErr: "unsupported operation chmod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fsFaultConfig(action_kit_api.PrepareActionRequestBody{Config: tt.config})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/devmapper"
+	"github.com/steadybit/extension-host/exthost/mounts"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	ioLatencyModeDelay = "DELAY"
+	ioLatencyModeError = "ERROR"
+)
+
+type ioLatencyAction struct{}
+
+type IoLatencyActionState struct {
+	Mode          string
+	MountPoint    string
+	DeviceName    string
+	OriginalTable []devmapper.Target
+	FaultTable    []devmapper.Target
+	TableApplied  bool
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[IoLatencyActionState]         = (*ioLatencyAction)(nil)
+	_ action_kit_sdk.ActionWithStop[IoLatencyActionState] = (*ioLatencyAction)(nil)
+)
+
+func NewIoLatencyAction() action_kit_sdk.Action[IoLatencyActionState] {
+	return &ioLatencyAction{}
+}
+
+func (a *ioLatencyAction) NewEmptyState() IoLatencyActionState {
+	return IoLatencyActionState{}
+}
+
+func (a *ioLatencyAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.io-latency", BaseActionID),
+		Label:       "IO Latency",
+		Description: "Injects latency or errors into all I/O of the block device backing the given path for the given duration. The device must be a linear device-mapper device (e.g. an LVM logical volume).",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(stressIOIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("Resource"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the I/O be affected?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:         "path",
+				Label:        "Path",
+				Description:  extutil.Ptr("A path on the host, the block device of the filesystem containing it is affected."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr("/"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(2),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  extutil.Ptr("Delay all I/O or let the I/O fail."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(ioLatencyModeDelay),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(3),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Delay I/O",
+						Value: ioLatencyModeDelay,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Fail I/O",
+						Value: ioLatencyModeError,
+					},
+				}),
+			},
+			{
+				Name:         "delay",
+				Label:        "Delay",
+				Description:  extutil.Ptr("The delay added to each I/O request when using mode 'Delay I/O'."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("100ms"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(4),
+			},
+			{
+				Name:         "upInterval",
+				Label:        "Up Interval",
+				Description:  extutil.Ptr("When using mode 'Fail I/O', the device works normally for this interval before failing again."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("0s"),
+				Required:     extutil.Ptr(true),
+				Advanced:     extutil.Ptr(true),
+				Order:        extutil.Ptr(5),
+			},
+			{
+				Name:         "downInterval",
+				Label:        "Down Interval",
+				Description:  extutil.Ptr("When using mode 'Fail I/O', all I/O fails for this interval."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("1s"),
+				Required:     extutil.Ptr(true),
+				Advanced:     extutil.Ptr(true),
+				Order:        extutil.Ptr(6),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *ioLatencyAction) Prepare(ctx context.Context, state *IoLatencyActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	path := extutil.ToString(request.Config["path"])
+	hostMounts, err := mounts.ReadHostMountInfo()
+	if err != nil {
+		return nil, err
+	}
+	mount, err := mounts.FindMount(hostMounts, path)
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Failed to find the mount for %s", path),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	deviceName, err := devmapper.DeviceName(mount.Major, mount.Minor)
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("The device of %s (%s) is not supported", mount.MountPoint, mount.Source),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	table, err := devmapper.ReadTable(ctx, deviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := extutil.ToString(request.Config["mode"])
+	var faultTable []devmapper.Target
+	switch mode {
+	case ioLatencyModeDelay:
+		delay := time.Duration(extutil.ToInt64(request.Config["delay"])) * time.Millisecond
+		if delay <= 0 {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Delay must be greater than 0",
+					Status: extutil.Ptr(action_kit_api.Errored),
+				}),
+			}, nil
+		}
+		faultTable, err = devmapper.DelayTable(table, delay)
+	case ioLatencyModeError:
+		up := time.Duration(extutil.ToInt64(request.Config["upInterval"])) * time.Millisecond
+		down := time.Duration(extutil.ToInt64(request.Config["downInterval"])) * time.Millisecond
+		faultTable, err = devmapper.FlakeyTable(table, up, down)
+	default:
+		return nil, fmt.Errorf("invalid mode %s", mode)
+	}
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Cannot inject faults into %s", deviceName),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	state.Mode = mode
+	state.MountPoint = mount.MountPoint
+	state.DeviceName = deviceName
+	state.OriginalTable = table
+	state.FaultTable = faultTable
+	return nil, nil
+}
+
+func (a *ioLatencyAction) Start(ctx context.Context, state *IoLatencyActionState) (*action_kit_api.StartResult, error) {
+	if err := devmapper.LoadTable(ctx, state.DeviceName, state.FaultTable); err != nil {
+		log.Error().Err(err).Str("device", state.DeviceName).Msg("Failed to inject I/O faults")
+		if revertErr := devmapper.LoadTable(context.Background(), state.DeviceName, state.OriginalTable); revertErr != nil {
+			log.Error().Err(revertErr).Str("device", state.DeviceName).Msg("Failed to restore device-mapper table")
+		}
+		return nil, err
+	}
+	state.TableApplied = true
+
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Injected I/O faults into %s (%s) using table '%s'", state.DeviceName, state.MountPoint, state.FaultTable[0].String()),
+			},
+		}),
+	}, nil
+}
+
+func (a *ioLatencyAction) Stop(ctx context.Context, state *IoLatencyActionState) (*action_kit_api.StopResult, error) {
+	if !state.TableApplied {
+		log.Debug().Msg("No I/O faults injected, skipping revert")
+		return nil, nil
+	}
+
+	if err := devmapper.LoadTable(ctx, state.DeviceName, state.OriginalTable); err != nil {
+		log.Error().Err(err).Str("device", state.DeviceName).Msg("Failed to restore device-mapper table")
+		return nil, err
+	}
+	state.TableApplied = false
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Restored original table of %s (%s)", state.DeviceName, state.MountPoint),
+			},
+		}),
+	}, nil
+}
@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/cgroup"
+	"github.com/steadybit/extension-host/exthost/mounts"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const bytesPerMegabyte = 1024 * 1024
+
+type ioThrottleAction struct {
+	service bool
+}
+
+type IoThrottleActionState struct {
+	Cgroups       []cgroup.Cgroup
+	Device        string
+	Limits        cgroup.IoLimits
+	OriginalIoMax map[string]string
+	Applied       bool
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[IoThrottleActionState]         = (*ioThrottleAction)(nil)
+	_ action_kit_sdk.ActionWithStop[IoThrottleActionState] = (*ioThrottleAction)(nil)
+)
+
+func NewIoThrottleAction() action_kit_sdk.Action[IoThrottleActionState] {
+	return &ioThrottleAction{}
+}
+
+// NewIoThrottleServiceAction limits the cgroup of a discovered systemd service
+func NewIoThrottleServiceAction() action_kit_sdk.Action[IoThrottleActionState] {
+	return &ioThrottleAction{service: true}
+}
+
+func (a *ioThrottleAction) NewEmptyState() IoThrottleActionState {
+	return IoThrottleActionState{}
+}
+
+func (a *ioThrottleAction) Describe() action_kit_api.ActionDescription {
+	d := action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.io-throttle", BaseActionID),
+		Label:       "Throttle IO of Process",
+		Description: "Limits the disk bandwidth and IOPS of the cgroup of the targeted processes or systemd unit for the given duration. Requires cgroup v2.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(stressIOIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("Resource"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the IO be throttled?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:        "process",
+				Label:       "Process",
+				Description: extutil.Ptr("PID or string to match the process name. Ignored when a systemd unit is given."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(2),
+			},
+			{
+				Name:        "unit",
+				Label:       "Systemd Unit",
+				Description: extutil.Ptr("Name of the systemd unit to throttle, e.g. nginx.service."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(3),
+			},
+			{
+				Name:         "path",
+				Label:        "Path",
+				Description:  extutil.Ptr("A path on the host, the IO to the block device of the filesystem containing it is throttled."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr("/"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(4),
+			},
+			{
+				Name:         "readBps",
+				Label:        "Read Bandwidth (MB/s)",
+				Description:  extutil.Ptr("Maximum read bandwidth in Megabytes per second. 0 for unlimited."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("1"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(5),
+			},
+			{
+				Name:         "writeBps",
+				Label:        "Write Bandwidth (MB/s)",
+				Description:  extutil.Ptr("Maximum write bandwidth in Megabytes per second. 0 for unlimited."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("1"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(6),
+			},
+			{
+				Name:         "readIops",
+				Label:        "Read IOPS",
+				Description:  extutil.Ptr("Maximum read operations per second. 0 for unlimited."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("0"),
+				Required:     extutil.Ptr(true),
+				Advanced:     extutil.Ptr(true),
+				Order:        extutil.Ptr(7),
+			},
+			{
+				Name:         "writeIops",
+				Label:        "Write IOPS",
+				Description:  extutil.Ptr("Maximum write operations per second. 0 for unlimited."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("0"),
+				Required:     extutil.Ptr(true),
+				Advanced:     extutil.Ptr(true),
+				Order:        extutil.Ptr(8),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+	if a.service {
+		d = serviceActionDescription(d, "process", "unit")
+		d.Label = "Throttle IO of Service"
+		d.Description = "Limits the disk bandwidth and IOPS of the cgroup of the systemd service for the given duration. Requires cgroup v2."
+	}
+	return d
+}
+
+func (a *ioThrottleAction) Prepare(ctx context.Context, state *IoThrottleActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	if !cgroup.IsV2() {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Throttling IO requires cgroup v2",
+				Status: extutil.Ptr(action_kit_api.Errored),
+			}),
+		}, nil
+	}
+
+	limits := cgroup.IoLimits{
+		ReadBps:   extutil.ToUInt64(request.Config["readBps"]) * bytesPerMegabyte,
+		WriteBps:  extutil.ToUInt64(request.Config["writeBps"]) * bytesPerMegabyte,
+		ReadIops:  extutil.ToUInt64(request.Config["readIops"]),
+		WriteIops: extutil.ToUInt64(request.Config["writeIops"]),
+	}
+	if limits.IsZero() {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "At least one limit is required",
+				Status: extutil.Ptr(action_kit_api.Errored),
+			}),
+		}, nil
+	}
+
+	path := extutil.ToString(request.Config["path"])
+	hostMounts, err := mounts.ReadHostMountInfo()
+	if err != nil {
+		return nil, err
+	}
+	mount, err := mounts.FindMount(hostMounts, path)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find the mount for %s", path), err)
+	}
+	device, err := cgroup.IoDevice(mount.Major, mount.Minor)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find the block device for %s", path), err)
+	}
+
+	cgroups, err := resolveActionCgroups(ctx, a.service, request, "io")
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Failed to find the cgroups to throttle",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	original := make(map[string]string, len(cgroups))
+	for _, c := range cgroups {
+		line, err := cgroup.ReadIoMax(c, device)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to read io.max of %s, is the io controller enabled?", c), err)
+		}
+		original[c.Path] = line
+	}
+
+	state.Cgroups = cgroups
+	state.Device = device
+	state.Limits = limits
+	state.OriginalIoMax = original
+	return nil, nil
+}
+
+func (a *ioThrottleAction) Start(_ context.Context, state *IoThrottleActionState) (*action_kit_api.StartResult, error) {
+	paths := make([]string, 0, len(state.Cgroups))
+	for i, c := range state.Cgroups {
+		if err := cgroup.WriteIoMax(c, state.Device, state.Limits); err != nil {
+			log.Error().Err(err).Str("cgroup", c.Path).Msg("Failed to throttle IO")
+			if revertErr := a.restore(state.Cgroups[:i], state); revertErr != nil {
+				log.Error().Err(revertErr).Msg("Failed to restore io.max")
+			}
+			return nil, err
+		}
+		paths = append(paths, c.Path)
+	}
+	state.Applied = true
+
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Throttled IO on device %s to '%s' for %s", state.Device, state.Limits, strings.Join(paths, ", ")),
+			},
+		}),
+	}, nil
+}
+
+func (a *ioThrottleAction) Stop(_ context.Context, state *IoThrottleActionState) (*action_kit_api.StopResult, error) {
+	if !state.Applied {
+		log.Debug().Msg("No IO limits applied, skipping revert")
+		return nil, nil
+	}
+
+	if err := a.restore(state.Cgroups, state); err != nil {
+		return nil, err
+	}
+	state.Applied = false
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Restored IO limits on device %s", state.Device),
+			},
+		}),
+	}, nil
+}
+
+func (a *ioThrottleAction) restore(cgroups []cgroup.Cgroup, state *IoThrottleActionState) error {
+	var errs error
+	for _, c := range cgroups {
+		if err := cgroup.RestoreIoMax(c, state.Device, state.OriginalIoMax[c.Path]); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				log.Info().Str("cgroup", c.Path).Msg("Cgroup was removed, nothing to restore")
+				continue
+			}
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
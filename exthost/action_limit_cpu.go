@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/cgroup"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	limitCpuModeQuota  = "QUOTA"
+	limitCpuModeWeight = "WEIGHT"
+	cpuQuotaPeriod     = 100 * time.Millisecond
+)
+
+type limitCpuAction struct {
+	service bool
+}
+
+type LimitCpuActionState struct {
+	Cgroups          []cgroup.Cgroup
+	Mode             string
+	CpuPercentage    uint64
+	Weight           uint64
+	OriginalSettings map[string]cgroup.CpuSettings
+	Applied          bool
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[LimitCpuActionState]         = (*limitCpuAction)(nil)
+	_ action_kit_sdk.ActionWithStop[LimitCpuActionState] = (*limitCpuAction)(nil)
+)
+
+func NewLimitCpuAction() action_kit_sdk.Action[LimitCpuActionState] {
+	return &limitCpuAction{}
+}
+
+// NewLimitCpuServiceAction limits the cgroup of a discovered systemd service
+func NewLimitCpuServiceAction() action_kit_sdk.Action[LimitCpuActionState] {
+	return &limitCpuAction{service: true}
+}
+
+func (a *limitCpuAction) NewEmptyState() LimitCpuActionState {
+	return LimitCpuActionState{}
+}
+
+func (a *limitCpuAction) Describe() action_kit_api.ActionDescription {
+	d := action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.limit-cpu", BaseActionID),
+		Label:       "Limit CPU of Process",
+		Description: "Limits the CPU of the cgroup of the targeted processes or systemd unit for the given duration.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(stressCPUIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("Resource"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the CPU be limited?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:        "process",
+				Label:       "Process",
+				Description: extutil.Ptr("PID or string to match the process name. Ignored when a systemd unit is given."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(2),
+			},
+			{
+				Name:        "unit",
+				Label:       "Systemd Unit",
+				Description: extutil.Ptr("Name of the systemd unit to limit, e.g. nginx.service."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(3),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  extutil.Ptr("*Quota:* Hard limit of the CPU time the processes may use.\n\n*Weight:* Lower the share of CPU time the processes get when competing with other processes."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(limitCpuModeQuota),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(4),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Quota",
+						Value: limitCpuModeQuota,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Weight",
+						Value: limitCpuModeWeight,
+					},
+				}),
+			},
+			{
+				Name:         "cpuPercentage",
+				Label:        "CPU Quota (% of one core)",
+				Description:  extutil.Ptr("CPU time available to the processes when using mode 'Quota', e.g. 50 for half a core or 200 for two cores."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("10"),
+				Required:     extutil.Ptr(true),
+				MinValue:     extutil.Ptr(1),
+				Order:        extutil.Ptr(5),
+			},
+			{
+				Name:         "weight",
+				Label:        "CPU Weight",
+				Description:  extutil.Ptr("CPU weight (1-10000) when using mode 'Weight'. The default weight of processes is 100."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("1"),
+				Required:     extutil.Ptr(true),
+				MinValue:     extutil.Ptr(1),
+				MaxValue:     extutil.Ptr(10000),
+				Order:        extutil.Ptr(6),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+	if a.service {
+		d = serviceActionDescription(d, "process", "unit")
+		d.Label = "Limit CPU of Service"
+		d.Description = "Limits the CPU of the cgroup of the systemd service for the given duration."
+	}
+	return d
+}
+
+func (a *limitCpuAction) Prepare(ctx context.Context, state *LimitCpuActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	mode := extutil.ToString(request.Config["mode"])
+	switch mode {
+	case limitCpuModeQuota:
+		state.CpuPercentage = extutil.ToUInt64(request.Config["cpuPercentage"])
+		if state.CpuPercentage == 0 {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "CPU quota must be greater than 0",
+					Status: extutil.Ptr(action_kit_api.Errored),
+				}),
+			}, nil
+		}
+	case limitCpuModeWeight:
+		state.Weight = extutil.ToUInt64(request.Config["weight"])
+		if state.Weight < 1 || state.Weight > 10000 {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "CPU weight must be between 1 and 10000",
+					Status: extutil.Ptr(action_kit_api.Errored),
+				}),
+			}, nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode %s", mode)
+	}
+	state.Mode = mode
+
+	cgroups, err := resolveActionCgroups(ctx, a.service, request, "cpu")
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Failed to find the cgroups to limit",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+
+	original := make(map[string]cgroup.CpuSettings, len(cgroups))
+	for _, c := range cgroups {
+		settings, err := cgroup.ReadCpuSettings(c)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to read the cpu settings of %s, is the cpu controller enabled?", c), err)
+		}
+		original[c.Path] = settings
+	}
+
+	state.Cgroups = cgroups
+	state.OriginalSettings = original
+	return nil, nil
+}
+
+func (a *limitCpuAction) Start(_ context.Context, state *LimitCpuActionState) (*action_kit_api.StartResult, error) {
+	paths := make([]string, 0, len(state.Cgroups))
+	for i, c := range state.Cgroups {
+		if err := a.limit(c, state); err != nil {
+			log.Error().Err(err).Str("cgroup", c.Path).Msg("Failed to limit CPU")
+			if revertErr := a.restore(state.Cgroups[:i+1], state); revertErr != nil {
+				log.Error().Err(revertErr).Msg("Failed to restore cpu settings")
+			}
+			return nil, err
+		}
+		paths = append(paths, c.Path)
+	}
+	state.Applied = true
+
+	limit := fmt.Sprintf("%d%% of one core", state.CpuPercentage)
+	if state.Mode == limitCpuModeWeight {
+		limit = fmt.Sprintf("weight %d", state.Weight)
+	}
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Limited CPU to %s for %s", limit, strings.Join(paths, ", ")),
+			},
+		}),
+	}, nil
+}
+
+func (a *limitCpuAction) limit(c cgroup.Cgroup, state *LimitCpuActionState) error {
+	if state.Mode == limitCpuModeWeight {
+		return cgroup.SetCpuWeight(c, state.Weight)
+	}
+	quota := time.Duration(state.CpuPercentage) * cpuQuotaPeriod / 100
+	return cgroup.LimitCpuQuota(c, quota, cpuQuotaPeriod)
+}
+
+func (a *limitCpuAction) Stop(_ context.Context, state *LimitCpuActionState) (*action_kit_api.StopResult, error) {
+	if !state.Applied {
+		log.Debug().Msg("No CPU limits applied, skipping revert")
+		return nil, nil
+	}
+
+	if err := a.restore(state.Cgroups, state); err != nil {
+		return nil, err
+	}
+	state.Applied = false
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: "Restored original CPU limits",
+			},
+		}),
+	}, nil
+}
+
+func (a *limitCpuAction) restore(cgroups []cgroup.Cgroup, state *LimitCpuActionState) error {
+	var errs error
+	for _, c := range cgroups {
+		if err := cgroup.RestoreCpuSettings(c, state.OriginalSettings[c.Path]); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				log.Info().Str("cgroup", c.Path).Msg("Cgroup was removed, nothing to restore")
+				continue
+			}
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/cgroup"
+	stopprocess "github.com/steadybit/extension-host/exthost/process"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	pauseProcessModeSignal = "SIGNAL"
+	pauseProcessModeCgroup = "CGROUP"
+)
+
+type pauseProcessAction struct {
+	service bool
+}
+
+type PauseProcessActionState struct {
+	Mode    string
+	Filter  stopprocess.Filter
+	Unit    string
+	Pids    []int
+	Cgroups []cgroup.Cgroup
+	// PausedPids and PausedCgroups hold what the attack paused, only these are resumed
+	PausedPids    []int
+	PausedCgroups []cgroup.Cgroup
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[PauseProcessActionState]         = (*pauseProcessAction)(nil)
+	_ action_kit_sdk.ActionWithStop[PauseProcessActionState] = (*pauseProcessAction)(nil)
+)
+
+func NewPauseProcessAction() action_kit_sdk.Action[PauseProcessActionState] {
+	return &pauseProcessAction{}
+}
+
+// NewPauseServiceAction pauses the processes of a discovered systemd service
+func NewPauseServiceAction() action_kit_sdk.Action[PauseProcessActionState] {
+	return &pauseProcessAction{service: true}
+}
+
+func (a *pauseProcessAction) NewEmptyState() PauseProcessActionState {
+	return PauseProcessActionState{}
+}
+
+func (a *pauseProcessAction) Describe() action_kit_api.ActionDescription {
+	d := action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.pause-process", BaseActionID),
+		Label:       "Pause Process",
+		Description: "Freezes the targeted processes for the given duration to simulate a hung process.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(stopProcessIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("State"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the processes be paused?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:        "process",
+				Label:       "Process",
+				Description: extutil.Ptr("PID or string to match the process name. Ignored when a systemd unit is given."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(2),
+			},
+			{
+				Name:        "unit",
+				Label:       "Systemd Unit",
+				Description: extutil.Ptr("Name of the systemd unit to pause, e.g. nginx.service. Only used by mode 'Cgroup Freeze'."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(3),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  extutil.Ptr("*Signal:* Send SIGSTOP to the matching processes and SIGCONT at the end. The parent of the processes is notified and processes started later are not paused.\n\n*Cgroup Freeze:* Freeze the cgroups of the matching processes or the systemd unit, including all processes started later. Requires cgroup v2."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(pauseProcessModeSignal),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(4),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Signal",
+						Value: pauseProcessModeSignal,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Cgroup Freeze",
+						Value: pauseProcessModeCgroup,
+					},
+				}),
+			},
+			{
+				Name:        "cmdline",
+				Label:       "Command Line",
+				Description: extutil.Ptr("Regular expression matching the full command line of the processes."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(5),
+			},
+			{
+				Name:        "user",
+				Label:       "User",
+				Description: extutil.Ptr("Name or uid of the user running the processes."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(6),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+	if a.service {
+		d = serviceActionDescription(d, "process", "unit")
+		d.Label = "Pause Service"
+		d.Description = "Freezes the processes of the systemd service for the given duration to simulate a hung service."
+	}
+	return d
+}
+
+func (a *pauseProcessAction) Prepare(ctx context.Context, state *PauseProcessActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	state.Filter = stopprocess.Filter{
+		Process: strings.TrimSpace(extutil.ToString(request.Config["process"])),
+		Cmdline: extutil.ToString(request.Config["cmdline"]),
+		User:    strings.TrimSpace(extutil.ToString(request.Config["user"])),
+	}
+	state.Unit = strings.TrimSpace(extutil.ToString(request.Config["unit"]))
+	state.Mode = extutil.ToString(request.Config["mode"])
+
+	if a.service {
+		// all processes of the service are paused, the command line and user may narrow them down
+		unit, cgroupPath, err := targetService(ctx, request.Target.Attributes)
+		if err != nil {
+			return a.refuse(err), nil
+		}
+		state.Unit = unit
+		state.Filter.Cgroup = cgroupPath
+	}
+
+	var refusal error
+	switch state.Mode {
+	case pauseProcessModeSignal:
+		state.Pids, refusal = pauseProcessPids(state.Filter)
+	case pauseProcessModeCgroup:
+		if !cgroup.IsV2() {
+			refusal = errors.New("freezing cgroups requires cgroup v2")
+			break
+		}
+		if state.Filter.Cgroup != "" {
+			state.Cgroups = []cgroup.Cgroup{cgroup.FromPath(state.Filter.Cgroup, "")}
+		} else {
+			state.Cgroups, refusal = resolveCgroups(ctx, state.Filter, state.Unit, "")
+		}
+		if refusal == nil {
+			own, err := cgroup.ProcessCgroup(ctx, os.Getpid(), "")
+			if err != nil {
+				return nil, extension_kit.ToError("Failed to read the cgroup of the extension", err)
+			}
+			refusal = checkFreezeAllowed(state.Cgroups, own)
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode %s", state.Mode)
+	}
+
+	if refusal != nil {
+		return a.refuse(refusal), nil
+	}
+	return nil, nil
+}
+
+func (a *pauseProcessAction) refuse(err error) *action_kit_api.PrepareResult {
+	return &action_kit_api.PrepareResult{
+		Error: extutil.Ptr(action_kit_api.ActionKitError{
+			Title:  "Cannot pause processes",
+			Status: extutil.Ptr(action_kit_api.Errored),
+			Detail: extutil.Ptr(err.Error()),
+		}),
+	}
+}
+
+// pauseProcessPids returns the processes to stop, init is never stopped
+func pauseProcessPids(filter stopprocess.Filter) ([]int, error) {
+	if filter.IsEmpty() {
+		return nil, errors.New("a process is required")
+	}
+	pids, err := stopprocess.FindProcesses(filter)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]int, 0, len(pids))
+	for _, pid := range pids {
+		if pid != 1 {
+			result = append(result, pid)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no process found matching '%s'", filter)
+	}
+	return result, nil
+}
+
+// checkFreezeAllowed refuses cgroups containing init or the extension itself
+func checkFreezeAllowed(cgroups []cgroup.Cgroup, own cgroup.Cgroup) error {
+	for _, c := range cgroups {
+		if c.IsRoot() || c.Path == "/init.scope" {
+			return fmt.Errorf("the cgroup %s contains init and can't be frozen", c)
+		}
+		if own.Path == c.Path || strings.HasPrefix(own.Path, c.Path+"/") {
+			return fmt.Errorf("the cgroup %s contains the extension and can't be frozen", c)
+		}
+	}
+	return nil
+}
+
+func (a *pauseProcessAction) Start(ctx context.Context, state *PauseProcessActionState) (*action_kit_api.StartResult, error) {
+	var paused []string
+	if state.Mode == pauseProcessModeCgroup {
+		for _, c := range state.Cgroups {
+			if frozen, err := cgroup.IsFrozen(c); err != nil {
+				return nil, a.revertStart(ctx, state, extension_kit.ToError(fmt.Sprintf("Failed to read the freezer state of %s", c), err))
+			} else if frozen {
+				log.Info().Str("cgroup", c.Path).Msg("Cgroup already frozen, it won't be thawed")
+				continue
+			}
+			// record the cgroup before freezing, so it is thawed even if waiting for the freeze fails
+			state.PausedCgroups = append(state.PausedCgroups, c)
+			if err := cgroup.Freeze(c); err != nil {
+				return nil, a.revertStart(ctx, state, extension_kit.ToError(fmt.Sprintf("Failed to freeze %s", c), err))
+			}
+			paused = append(paused, c.String())
+		}
+	} else {
+		for _, pid := range state.Pids {
+			state.PausedPids = append(state.PausedPids, pid)
+			if err := stopprocess.SignalProcesses([]int{pid}, syscall.SIGSTOP); err != nil {
+				return nil, a.revertStart(ctx, state, extension_kit.ToError(fmt.Sprintf("Failed to stop process %d", pid), err))
+			}
+			paused = append(paused, fmt.Sprintf("%d", pid))
+		}
+	}
+
+	what := "processes"
+	if state.Mode == pauseProcessModeCgroup {
+		what = "cgroups"
+	}
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Paused %s %s", what, strings.Join(paused, ", ")),
+			},
+		}),
+	}, nil
+}
+
+func (a *pauseProcessAction) revertStart(ctx context.Context, state *PauseProcessActionState, err error) error {
+	if _, revertErr := a.Stop(ctx, state); revertErr != nil {
+		log.Error().Err(revertErr).Msg("Failed to resume paused processes")
+	}
+	return err
+}
+
+func (a *pauseProcessAction) Stop(_ context.Context, state *PauseProcessActionState) (*action_kit_api.StopResult, error) {
+	if len(state.PausedPids) == 0 && len(state.PausedCgroups) == 0 {
+		log.Debug().Msg("No processes paused, skipping revert")
+		return nil, nil
+	}
+
+	// Only what failed to resume is left in the state. This matters when Start reverts itself, as its state is persisted
+	// for the Stop after it. Changes made in Stop itself are discarded by the SDK, failures there are only reported.
+	var errs []error
+	var resumed []string
+	var remainingCgroups []cgroup.Cgroup
+	for _, c := range state.PausedCgroups {
+		if err := cgroup.Thaw(c); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				log.Info().Str("cgroup", c.Path).Msg("Cgroup was removed, nothing to thaw")
+				continue
+			}
+			errs = append(errs, err)
+			remainingCgroups = append(remainingCgroups, c)
+			continue
+		}
+		resumed = append(resumed, c.String())
+	}
+	state.PausedCgroups = remainingCgroups
+
+	var remainingPids []int
+	for _, pid := range state.PausedPids {
+		// processes which exited in the meantime are skipped
+		if err := stopprocess.SignalProcesses([]int{pid}, syscall.SIGCONT); err != nil {
+			errs = append(errs, err)
+			remainingPids = append(remainingPids, pid)
+			continue
+		}
+		resumed = append(resumed, fmt.Sprintf("%d", pid))
+	}
+	state.PausedPids = remainingPids
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, extension_kit.ToError("Failed to resume paused processes", err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Resumed %s", strings.Join(resumed, ", ")),
+			},
+		}),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package exthost
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steadybit/extension-host/exthost/cgroup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFreezeAllowed(t *testing.T) {
+	own := cgroup.Cgroup{Path: "/system.slice/steadybit-extension-host.service"}
+	tests := []struct {
+		name        string
+		path        string
+		wantedError string
+	}{
+		{name: "service", path: "/system.slice/nginx.service"},
+		{name: "root", path: "/", wantedError: "contains init"},
+		{name: "init", path: "/init.scope", wantedError: "contains init"},
+		{name: "own", path: "/system.slice/steadybit-extension-host.service", wantedError: "contains the extension"},
+		{name: "parent of own", path: "/system.slice", wantedError: "contains the extension"},
+		{name: "sibling with same prefix", path: "/system.slice/steadybit-extension-host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFreezeAllowed([]cgroup.Cgroup{{Path: tt.path}}, own)
+			if tt.wantedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantedError)
+			}
+		})
+	}
+}
+
+func TestActionPauseProcess_SignalStartStop(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+	defer func() { _ = cmd.Process.Kill(); _ = cmd.Wait() }()
+
+	action := NewPauseProcessAction()
+	state := PauseProcessActionState{Mode: pauseProcessModeSignal, Pids: []int{cmd.Process.Pid}}
+
+	_, err := action.Start(context.Background(), &state)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return processState(cmd.Process.Pid) == "T" }, time.Second, 10*time.Millisecond)
+
+	_, err = action.(*pauseProcessAction).Stop(context.Background(), &state)
+	require.NoError(t, err)
+	assert.Empty(t, state.PausedPids)
+	assert.Eventually(t, func() bool { return processState(cmd.Process.Pid) == "S" }, time.Second, 10*time.Millisecond)
+
+	// a second stop is a no-op
+	result, err := action.(*pauseProcessAction).Stop(context.Background(), &state)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func processState(pid int) string {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return fields[0]
+}
@@ -0,0 +1,331 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-host/exthost/hostns"
+	"github.com/steadybit/extension-host/exthost/mounts"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	readOnlyModeRemount = "REMOUNT"
+	readOnlyModeBind    = "BIND"
+)
+
+// criticalHostPaths must not be hidden by a read-only bind mount, as the host, the container runtime or the extension depend on them.
+var criticalHostPaths = []string{"/proc", "/sys", "/dev", "/run", "/var/run", "/var/lib/containerd", "/var/lib/docker", "/var/lib/kubelet", "/opt/steadybit"}
+
+type readOnlyFsAction struct{}
+
+type ReadOnlyFsActionState struct {
+	Mode string
+	Path string
+	// MountId identifies the read-only bind mount, Stop only unmounts this mount
+	MountId int
+	Applied bool
+}
+
+var readHostMounts = mounts.ReadHostMountInfo
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[ReadOnlyFsActionState]         = (*readOnlyFsAction)(nil)
+	_ action_kit_sdk.ActionWithStop[ReadOnlyFsActionState] = (*readOnlyFsAction)(nil)
+)
+
+func NewReadOnlyFsAction() action_kit_sdk.Action[ReadOnlyFsActionState] {
+	return &readOnlyFsAction{}
+}
+
+func (a *readOnlyFsAction) NewEmptyState() ReadOnlyFsActionState {
+	return ReadOnlyFsActionState{}
+}
+
+func (a *readOnlyFsAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.read-only-fs", BaseActionID),
+		Label:       "Read-Only Filesystem",
+		Description: "Makes a filesystem or directory of the host read-only for the given duration, like a filesystem remounted read-only after errors.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(fillDiskIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("Resource"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the filesystem be read-only?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:        "path",
+				Label:       "Path",
+				Description: extutil.Ptr("A path on the host."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(true),
+				Order:       extutil.Ptr(2),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  extutil.Ptr("*Remount:* Remount the whole filesystem containing the path read-only. Fails if files on it are open for writing.\n\n*Bind Mount:* Bind mount the directory read-only onto itself, other paths of the filesystem stay writable."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(readOnlyModeRemount),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(3),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Remount",
+						Value: readOnlyModeRemount,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Bind Mount",
+						Value: readOnlyModeBind,
+					},
+				}),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *readOnlyFsAction) Prepare(_ context.Context, state *ReadOnlyFsActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Clean(extutil.ToString(request.Config["path"]))
+	if !filepath.IsAbs(path) {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Path must be absolute, got '%s'", path),
+				Status: extutil.Ptr(action_kit_api.Errored),
+			}),
+		}, nil
+	}
+
+	hostMounts, err := mounts.ReadHostMountInfo()
+	if err != nil {
+		return nil, err
+	}
+	ownMounts, err := mounts.ReadMountInfo(os.Getpid())
+	if err != nil {
+		return nil, err
+	}
+
+	mode := extutil.ToString(request.Config["mode"])
+	var refusal error
+	switch mode {
+	case readOnlyModeRemount:
+		mount, err := mounts.FindMount(hostMounts, path)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to find the mount for %s", path), err)
+		}
+		if mount.ReadOnly() {
+			refusal = fmt.Errorf("%s is already mounted read-only", mount.MountPoint)
+		} else {
+			refusal = checkRemountAllowed(*mount, hostMounts, ownMounts)
+		}
+		path = mount.MountPoint
+	case readOnlyModeBind:
+		if fi, err := os.Stat(hostfs.Path(path)); err != nil || !fi.IsDir() {
+			refusal = fmt.Errorf("%s is not a directory on the host", path)
+		} else {
+			refusal = checkBindAllowed(path)
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode %s", mode)
+	}
+
+	if refusal != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Cannot make %s read-only", path),
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(refusal.Error()),
+			}),
+		}, nil
+	}
+
+	state.Mode = mode
+	state.Path = path
+	return nil, nil
+}
+
+// checkRemountAllowed refuses the root filesystem and filesystems backing critical host paths or the extension itself.
+// A remount changes the superblock, every bind mount of the same device becomes read-only as well.
+func checkRemountAllowed(mount mounts.MountInfo, hostMounts, ownMounts []mounts.MountInfo) error {
+	if mount.MountPoint == "/" {
+		return fmt.Errorf("remounting the root filesystem is not supported, use mode 'Bind Mount' for a directory instead")
+	}
+	devices := extensionDevices(hostMounts, ownMounts)
+	if path, ok := devices[mount.Device()]; ok {
+		return fmt.Errorf("the filesystem %s (device %s) is used by the extension for %s", mount.MountPoint, mount.Device(), path)
+	}
+	for _, critical := range criticalHostPaths {
+		if m, err := mounts.FindMount(hostMounts, critical); err == nil && m.Device() == mount.Device() {
+			return fmt.Errorf("the filesystem %s (device %s) backs %s, which is critical for the host or the extension", mount.MountPoint, mount.Device(), critical)
+		}
+	}
+	return nil
+}
+
+// checkBindAllowed refuses directories containing or below paths critical for the host and the extension.
+func checkBindAllowed(path string) error {
+	if path == "/" {
+		return fmt.Errorf("the root directory can't be made read-only, choose a directory below")
+	}
+	for _, critical := range criticalHostPaths {
+		if critical == path || strings.HasPrefix(critical, path+"/") || strings.HasPrefix(path, critical+"/") {
+			return fmt.Errorf("%s is critical for the host or the extension", critical)
+		}
+	}
+	return nil
+}
+
+// extensionDevices returns the devices backing the extension's own directories mapped to the directory.
+// For an overlay root filesystem the devices of its upper and work directories are returned as well.
+func extensionDevices(hostMounts, ownMounts []mounts.MountInfo) map[string]string {
+	paths := []string{"/", os.TempDir()}
+	if exe, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Dir(exe))
+	}
+
+	devices := make(map[string]string)
+	for _, path := range paths {
+		own, err := mounts.FindMount(ownMounts, path)
+		if err != nil {
+			continue
+		}
+		devices[own.Device()] = path
+		if own.FsType != "overlay" {
+			continue
+		}
+		for _, m := range hostMounts {
+			if m.Device() != own.Device() {
+				continue
+			}
+			for _, option := range m.SuperOptions {
+				key, dir, _ := strings.Cut(option, "=")
+				if key != "upperdir" && key != "workdir" {
+					continue
+				}
+				if backing, err := mounts.FindMount(hostMounts, dir); err == nil {
+					devices[backing.Device()] = path
+				}
+			}
+		}
+	}
+	return devices
+}
+
+func (a *readOnlyFsAction) Start(ctx context.Context, state *ReadOnlyFsActionState) (*action_kit_api.StartResult, error) {
+	if state.Mode == readOnlyModeBind {
+		if _, err := hostns.Run(ctx, "mount", "--bind", "--", state.Path, state.Path); err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to bind mount %s", state.Path), err)
+		}
+		if _, err := hostns.Run(ctx, "mount", "-o", "remount,bind,ro", "--", state.Path); err != nil {
+			if _, revertErr := hostns.Run(context.Background(), "umount", "--", state.Path); revertErr != nil {
+				log.Error().Err(revertErr).Str("path", state.Path).Msg("Failed to remove bind mount")
+			}
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to make %s read-only", state.Path), err)
+		}
+		hostMounts, err := readHostMounts()
+		if err == nil {
+			if m := topmostMount(hostMounts, state.Path); m != nil {
+				state.MountId = m.MountId
+			} else {
+				err = fmt.Errorf("no mount found on %s", state.Path)
+			}
+		}
+		if err != nil {
+			if _, revertErr := hostns.Run(context.Background(), "umount", "--", state.Path); revertErr != nil {
+				log.Error().Err(revertErr).Str("path", state.Path).Msg("Failed to remove bind mount")
+			}
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to read the read-only bind mount of %s", state.Path), err)
+		}
+	} else {
+		if _, err := hostns.Run(ctx, "mount", "-o", "remount,ro", "--", state.Path); err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to remount %s read-only, are files open for writing?", state.Path), err)
+		}
+	}
+	state.Applied = true
+
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Made %s read-only", state.Path),
+			},
+		}),
+	}, nil
+}
+
+// topmostMount returns the mount stacked last on exactly the given mount point, nil if nothing is mounted there.
+func topmostMount(hostMounts []mounts.MountInfo, path string) *mounts.MountInfo {
+	m, err := mounts.FindMount(hostMounts, path)
+	if err != nil || m.MountPoint != filepath.Clean(path) {
+		return nil
+	}
+	return m
+}
+
+func (a *readOnlyFsAction) Stop(ctx context.Context, state *ReadOnlyFsActionState) (*action_kit_api.StopResult, error) {
+	if !state.Applied {
+		log.Debug().Msg("No filesystem made read-only, skipping revert")
+		return nil, nil
+	}
+
+	if state.Mode == readOnlyModeBind {
+		hostMounts, err := readHostMounts()
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to read the mounts of the host", err)
+		}
+		// a retried Stop must not unmount what is below the bind mount, which might be a filesystem of the user
+		if m := topmostMount(hostMounts, state.Path); m == nil || m.MountId != state.MountId {
+			log.Info().Str("path", state.Path).Msg("Read-only bind mount is already removed")
+		} else if _, err := hostns.Run(ctx, "umount", "--", state.Path); err != nil {
+			log.Warn().Err(err).Str("path", state.Path).Msg("Failed to unmount read-only bind mount, detaching it lazily")
+			if _, err := hostns.Run(ctx, "umount", "--lazy", "--", state.Path); err != nil {
+				return nil, extension_kit.ToError(fmt.Sprintf("Failed to remove the read-only bind mount of %s", state.Path), err)
+			}
+		}
+	} else {
+		if _, err := hostns.Run(ctx, "mount", "-o", "remount,rw", "--", state.Path); err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to remount %s read-write", state.Path), err)
+		}
+	}
+	state.Applied = false
+
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Made %s writable again", state.Path),
+			},
+		}),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package exthost
+
+import (
+	"context"
+	"github.com/steadybit/extension-host/exthost/mounts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_checkRemountAllowed(t *testing.T) {
+	hostMounts := []mounts.MountInfo{
+		{MountPoint: "/", Major: 8, Minor: 1, FsType: "ext4"},
+		{MountPoint: "/var/lib/containerd", Major: 8, Minor: 2, FsType: "ext4"},
+		{MountPoint: "/data", Major: 8, Minor: 3, FsType: "xfs"},
+		{MountPoint: "/var", Major: 8, Minor: 4, FsType: "ext4"},
+		{MountPoint: "/srv", Major: 8, Minor: 5, FsType: "xfs"},
+		{MountPoint: "/var/lib/kubelet", Root: "/kubelet", Major: 8, Minor: 5, FsType: "xfs"},
+		{MountPoint: "/run/containerd/io.containerd.runtime.v2.task/k8s.io/abc/rootfs", Major: 0, Minor: 50, FsType: "overlay",
+			SuperOptions: []string{"rw", "lowerdir=/var/lib/containerd/snapshots/1/fs:/var/lib/containerd/snapshots/2/fs", "upperdir=/var/lib/containerd/snapshots/3/fs", "workdir=/var/lib/containerd/snapshots/3/work"}},
+	}
+	ownMounts := []mounts.MountInfo{
+		{MountPoint: "/", Major: 0, Minor: 50, FsType: "overlay"},
+	}
+
+	assert.ErrorContains(t, checkRemountAllowed(hostMounts[0], hostMounts, ownMounts), "root filesystem is not supported")
+	assert.ErrorContains(t, checkRemountAllowed(hostMounts[1], hostMounts, ownMounts), "is used by the extension")
+	assert.NoError(t, checkRemountAllowed(hostMounts[2], hostMounts, ownMounts))
+	assert.ErrorContains(t, checkRemountAllowed(hostMounts[3], hostMounts, ownMounts), "backs /var/")
+	// the kubelet directory is a bind mount of a directory on /srv, remounting /srv would make it read-only too
+	assert.ErrorContains(t, checkRemountAllowed(hostMounts[4], hostMounts, ownMounts), "backs /var/lib/kubelet")
+}
+
+func Test_checkBindAllowed(t *testing.T) {
+	assert.Error(t, checkBindAllowed("/"))
+	assert.Error(t, checkBindAllowed("/var"))
+	assert.Error(t, checkBindAllowed("/var/lib/kubelet/pods"))
+	assert.Error(t, checkBindAllowed("/proc/sys"))
+	assert.NoError(t, checkBindAllowed("/var/log"))
+	assert.NoError(t, checkBindAllowed("/srv/data"))
+}
+
+func Test_topmostMount(t *testing.T) {
+	hostMounts := []mounts.MountInfo{
+		{MountId: 1, MountPoint: "/"},
+		{MountId: 2, MountPoint: "/srv/data"},
+		{MountId: 3, MountPoint: "/srv/data", Root: "/srv/data"},
+	}
+	assert.Equal(t, 3, topmostMount(hostMounts, "/srv/data").MountId)
+	assert.Equal(t, 3, topmostMount(hostMounts, "/srv/data/").MountId)
+	assert.Nil(t, topmostMount(hostMounts, "/srv"))
+}
+
+func TestActionReadOnlyFs_StopSkipsForeignMount(t *testing.T) {
+	original := readHostMounts
+	t.Cleanup(func() { readHostMounts = original })
+	// the bind mount of the attack is gone, /srv/data is a filesystem of the user
+	readHostMounts = func() ([]mounts.MountInfo, error) {
+		return []mounts.MountInfo{
+			{MountId: 1, MountPoint: "/"},
+			{MountId: 2, MountPoint: "/srv/data"},
+		}, nil
+	}
+
+	state := ReadOnlyFsActionState{Mode: readOnlyModeBind, Path: "/srv/data", MountId: 42, Applied: true}
+	_, err := (&readOnlyFsAction{}).Stop(context.Background(), &state)
+	require.NoError(t, err)
+	assert.False(t, state.Applied)
+}
@@ -7,7 +7,9 @@ package exthost
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,17 +19,24 @@ import (
 	stopprocess "github.com/steadybit/extension-host/exthost/process"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
+	"golang.org/x/sys/unix"
 )
 
 type stopProcessAction struct {
 	processStoppers sync.Map
+	service         bool
 }
 
 type StopProcessActionState struct {
 	ExecutionID   uuid.UUID
 	Delay         time.Duration
 	ProcessFilter string //pid or executable name
+	Cmdline       string //regex matching the command line
+	User          string
+	ParentPid     int
+	Cgroup        string //cgroup of the targeted service
 	Graceful      bool
+	Signal        string //overrides graceful if set
 	Deadline      time.Time
 	Duration      time.Duration
 }
@@ -42,16 +51,21 @@ func NewStopProcessAction() action_kit_sdk.Action[StopProcessActionState] {
 	return &stopProcessAction{}
 }
 
+// NewStopServiceProcessesAction stops or signals the processes of a discovered systemd service
+func NewStopServiceProcessesAction() action_kit_sdk.Action[StopProcessActionState] {
+	return &stopProcessAction{service: true}
+}
+
 func (a *stopProcessAction) NewEmptyState() StopProcessActionState {
 	return StopProcessActionState{}
 }
 
 // Describe returns the action description for the platform with all required information.
 func (a *stopProcessAction) Describe() action_kit_api.ActionDescription {
-	return action_kit_api.ActionDescription{
+	d := action_kit_api.ActionDescription{
 		Id:          stopProcessActionID,
 		Label:       "Stop Processes",
-		Description: "Stops targeted processes in the given duration.",
+		Description: "Stops or signals targeted processes in the given duration.",
 		Version:     extbuild.GetSemverVersionStringOrUnknown(),
 		Icon:        extutil.Ptr(stopProcessIcon),
 		TargetSelection: extutil.Ptr(action_kit_api.TargetSelection{
@@ -83,9 +97,9 @@ func (a *stopProcessAction) Describe() action_kit_api.ActionDescription {
 			{
 				Name:        "process",
 				Label:       "Process",
-				Description: extutil.Ptr("PID or string to match the process name or command."),
+				Description: extutil.Ptr("PID or string to match the process name or command. Can be left empty if other filters are given."),
 				Type:        action_kit_api.ActionParameterTypeString,
-				Required:    extutil.Ptr(true),
+				Required:    extutil.Ptr(false),
 				Order:       extutil.Ptr(1),
 			},
 			{
@@ -115,9 +129,64 @@ func (a *stopProcessAction) Describe() action_kit_api.ActionDescription {
 				Advanced:     extutil.Ptr(true),
 				Order:        extutil.Ptr(1),
 			},
+			{
+				Name:        "signal",
+				Label:       "Signal",
+				Description: extutil.Ptr("The signal to send instead of TERM or KILL, e.g. HUP to reload or SEGV to produce a core dump. Use the Pause Process attack to stop processes temporarily."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(2),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "SIGTERM", Value: "SIGTERM"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGKILL", Value: "SIGKILL"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGINT", Value: "SIGINT"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGHUP", Value: "SIGHUP"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGQUIT", Value: "SIGQUIT"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGSTOP", Value: "SIGSTOP"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGCONT", Value: "SIGCONT"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGSEGV", Value: "SIGSEGV"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGABRT", Value: "SIGABRT"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGUSR1", Value: "SIGUSR1"},
+					action_kit_api.ExplicitParameterOption{Label: "SIGUSR2", Value: "SIGUSR2"},
+				}),
+			},
+			{
+				Name:        "cmdline",
+				Label:       "Command Line",
+				Description: extutil.Ptr("Regular expression matching the full command line of the processes."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(3),
+			},
+			{
+				Name:        "user",
+				Label:       "User",
+				Description: extutil.Ptr("Name or uid of the user running the processes."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(4),
+			},
+			{
+				Name:        "ppid",
+				Label:       "Parent PID",
+				Description: extutil.Ptr("PID of the parent of the processes."),
+				Type:        action_kit_api.ActionParameterTypeInteger,
+				Required:    extutil.Ptr(false),
+				Advanced:    extutil.Ptr(true),
+				Order:       extutil.Ptr(5),
+			},
 		},
 		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
 	}
+	if a.service {
+		d = serviceActionDescription(d, "process")
+		d.Label = "Stop Service Processes"
+		d.Description = "Stops or signals the processes of the systemd service in the given duration."
+	}
+	return d
 }
 
 // Prepare is called before the action is started.
@@ -125,13 +194,31 @@ func (a *stopProcessAction) Describe() action_kit_api.ActionDescription {
 // It must not cause any harmful effects.
 // The passed in state is included in the subsequent calls to start/status/stop.
 // So the state should contain all information needed to execute the action and even more important: to be able to stop it.
-func (a *stopProcessAction) Prepare(_ context.Context, state *StopProcessActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+func (a *stopProcessAction) Prepare(ctx context.Context, state *StopProcessActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	_, err := CheckTargetHostname(request.Target.Attributes)
 	if err != nil {
 		return nil, err
 	}
-	processOrPid := extutil.ToString(request.Config["process"])
-	if processOrPid == "" {
+	filter := stopprocess.Filter{
+		Process:   strings.TrimSpace(extutil.ToString(request.Config["process"])),
+		Cmdline:   extutil.ToString(request.Config["cmdline"]),
+		User:      strings.TrimSpace(extutil.ToString(request.Config["user"])),
+		ParentPid: extutil.ToInt(request.Config["ppid"]),
+	}
+	if a.service {
+		_, cgroupPath, err := targetService(ctx, request.Target.Attributes)
+		if err != nil {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Invalid service",
+					Status: extutil.Ptr(action_kit_api.Errored),
+					Detail: extutil.Ptr(err.Error()),
+				}),
+			}, nil
+		}
+		filter.Cgroup = cgroupPath
+	}
+	if filter.IsEmpty() {
 		return &action_kit_api.PrepareResult{
 			Error: extutil.Ptr(action_kit_api.ActionKitError{
 				Title:  "Process is required",
@@ -139,7 +226,46 @@ func (a *stopProcessAction) Prepare(_ context.Context, state *StopProcessActionS
 			}),
 		}, nil
 	}
-	state.ProcessFilter = processOrPid
+	if err := filter.Validate(); err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Invalid process filter",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+	state.ExecutionID = request.ExecutionId
+	state.ProcessFilter = filter.Process
+	state.Cmdline = filter.Cmdline
+	state.User = filter.User
+	state.ParentPid = filter.ParentPid
+	state.Cgroup = filter.Cgroup
+
+	if signal := extutil.ToString(request.Config["signal"]); signal != "" {
+		parsed, err := stopprocess.ParseSignal(signal)
+		if err != nil {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Invalid signal",
+					Status: extutil.Ptr(action_kit_api.Errored),
+					Detail: extutil.Ptr(err.Error()),
+				}),
+			}, nil
+		}
+		// nothing would resume the processes, Pause Process sends SIGCONT at the end
+		switch parsed {
+		case syscall.SIGSTOP, syscall.SIGTSTP, syscall.SIGTTIN, syscall.SIGTTOU:
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Invalid signal",
+					Status: extutil.Ptr(action_kit_api.Errored),
+					Detail: extutil.Ptr(fmt.Sprintf("%s would leave the processes stopped, use the Pause Process attack instead", unix.SignalName(parsed))),
+				}),
+			}, nil
+		}
+		state.Signal = signal
+	}
 
 	parsedDuration := extutil.ToUInt64(request.Config["duration"])
 	if parsedDuration == 0 {
@@ -171,7 +297,17 @@ func (a *stopProcessAction) Prepare(_ context.Context, state *StopProcessActionS
 // You can mutate the state here.
 // You can use the result to return messages/errors/metrics or artifacts
 func (a *stopProcessAction) Start(_ context.Context, state *StopProcessActionState) (*action_kit_api.StartResult, error) {
-	stopper := newProcessStopper(state.ProcessFilter, state.Graceful, state.Delay, state.Duration)
+	signal := syscall.SIGKILL
+	if state.Signal != "" {
+		var err error
+		if signal, err = stopprocess.ParseSignal(state.Signal); err != nil {
+			return nil, err
+		}
+	} else if state.Graceful {
+		signal = syscall.SIGTERM
+	}
+	filter := state.filter()
+	stopper := newProcessStopper(filter, signal, state.Delay, state.Duration)
 
 	a.processStoppers.Store(state.ExecutionID, stopper)
 
@@ -180,7 +316,7 @@ func (a *stopProcessAction) Start(_ context.Context, state *StopProcessActionSta
 		Messages: extutil.Ptr([]action_kit_api.Message{
 			{
 				Level:   extutil.Ptr(action_kit_api.Info),
-				Message: fmt.Sprintf("Starting stop processes %s", state.ProcessFilter),
+				Message: fmt.Sprintf("Starting to send %s to processes %s", unix.SignalName(signal), filter),
 			},
 		}),
 	}, nil
@@ -201,12 +337,22 @@ func (a *stopProcessAction) Stop(_ context.Context, state *StopProcessActionStat
 	return nil, nil
 }
 
+func (s *StopProcessActionState) filter() stopprocess.Filter {
+	return stopprocess.Filter{
+		Process:   s.ProcessFilter,
+		Cmdline:   s.Cmdline,
+		User:      s.User,
+		ParentPid: s.ParentPid,
+		Cgroup:    s.Cgroup,
+	}
+}
+
 type processStopper struct {
 	stop  func()
 	start func()
 }
 
-func newProcessStopper(processFilter string, graceful bool, delay, duration time.Duration) *processStopper {
+func newProcessStopper(filter stopprocess.Filter, signal syscall.Signal, delay, duration time.Duration) *processStopper {
 	ctx, cancel := context.WithTimeout(context.Background(), duration)
 
 	start := func() {
@@ -214,10 +360,15 @@ func newProcessStopper(processFilter string, graceful bool, delay, duration time
 			for {
 				select {
 				case <-time.After(delay):
-					pids := stopprocess.FindProcessIds(processFilter)
+					pids, err := stopprocess.FindProcesses(filter)
+					if err != nil {
+						log.Error().Err(err).Msg("Failed to find processes")
+						continue
+					}
 					log.Debug().Msgf("Found %d processes to stop", len(pids))
-					err := stopprocess.StopProcesses(pids, !graceful)
-					log.Error().Err(err).Msg("Failed to stop processes")
+					if err := stopprocess.SignalProcesses(pids, signal); err != nil {
+						log.Error().Err(err).Msg("Failed to stop processes")
+					}
 				case <-ctx.Done():
 					return
 				}
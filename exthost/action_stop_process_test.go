@@ -65,6 +65,106 @@ func TestActionStopProcess_Prepare(t *testing.T) {
 			},
 
 			wantedError: "Duration is required",
+		}, {
+			name: "Should return config with filters and signal",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "10000",
+					"delay":    "1000",
+					"graceful": "true",
+					"cmdline":  "^nginx: worker",
+					"user":     "33",
+					"ppid":     "100",
+					"signal":   "SIGHUP",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedState: &StopProcessActionState{
+				Cmdline:   "^nginx: worker",
+				User:      "33",
+				ParentPid: 100,
+				Graceful:  true,
+				Signal:    "SIGHUP",
+				Duration:  10 * time.Second,
+				Delay:     1 * time.Second,
+			},
+		}, {
+			name: "Should return error without filter",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "10000",
+					"graceful": "true",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedError: "Process is required",
+		}, {
+			name: "Should return error for invalid regex",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "10000",
+					"cmdline":  "(",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedError: "Invalid process filter",
+		}, {
+			name: "Should return error for invalid signal",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "10000",
+					"process":  "tail",
+					"signal":   "SIGFOO",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedError: "Invalid signal",
+		}, {
+			name: "Should return error for stop signal",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "10000",
+					"process":  "tail",
+					"signal":   "STOP",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedError: "Invalid signal",
 		},
 	}
 	action := NewStopProcessAction()
@@ -93,6 +193,11 @@ func TestActionStopProcess_Prepare(t *testing.T) {
 				assert.Equal(t, tt.wantedState.ProcessFilter, state.ProcessFilter)
 				assert.Equal(t, tt.wantedState.Graceful, state.Graceful)
 				assert.Equal(t, tt.wantedState.Delay, state.Delay)
+				assert.Equal(t, tt.wantedState.Cmdline, state.Cmdline)
+				assert.Equal(t, tt.wantedState.User, state.User)
+				assert.Equal(t, tt.wantedState.ParentPid, state.ParentPid)
+				assert.Equal(t, tt.wantedState.Signal, state.Signal)
+				assert.Equal(t, request.ExecutionId, state.ExecutionID)
 				deadline := now.Add(state.Duration * time.Second)
 				assert.GreaterOrEqual(t, deadline.Unix(), state.Deadline.Unix())
 			}
@@ -0,0 +1,419 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-ps"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/cgroup"
+	stopprocess "github.com/steadybit/extension-host/exthost/process"
+	"github.com/steadybit/extension-host/exthost/systemd"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	stopServiceModeStop = "STOP"
+	stopServiceModeKill = "KILL"
+	stopServiceModeMask = "MASK"
+)
+
+// protectedServices run the container runtime, kubelet or the agent. Stopping them may kill the extension, which then can't start them again.
+var protectedServices = []string{
+	"containerd.service", "docker.service", "cri-docker.service", "crio.service",
+	"kubelet.service", "k3s.service", "k3s-agent.service", "rke2-server.service", "rke2-agent.service",
+	"steadybit-agent.service",
+}
+
+type stopServiceAction struct{}
+
+type StopServiceActionState struct {
+	Unit      string
+	Mode      string
+	Signal    string
+	Restart   string
+	LastState string
+	Masked    bool
+	Applied   bool
+	// CPUUsageNSec and UsageSampledAt hold the last sample to report the CPU usage since then
+	CPUUsageNSec   uint64
+	UsageSampledAt time.Time
+}
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[StopServiceActionState]           = (*stopServiceAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[StopServiceActionState] = (*stopServiceAction)(nil)
+	_ action_kit_sdk.ActionWithStop[StopServiceActionState]   = (*stopServiceAction)(nil)
+
+	readServiceUsage = systemd.ReadUsage
+)
+
+func NewStopServiceAction() action_kit_sdk.Action[StopServiceActionState] {
+	return &stopServiceAction{}
+}
+
+func (a *stopServiceAction) NewEmptyState() StopServiceActionState {
+	return StopServiceActionState{}
+}
+
+func (a *stopServiceAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.stop-service", BaseActionID),
+		Label:       "Stop Service",
+		Description: "Stops, kills or masks a systemd service for the given duration and starts it again afterwards.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(stopProcessIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         serviceTargetID,
+			SelectionTemplates: &serviceTargetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("State"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Widgets: extutil.Ptr([]action_kit_api.Widget{
+			serviceUsageWidget("Service Memory Usage", "service_memory_usage", "MiB"),
+			serviceUsageWidget("Service CPU Usage", "service_cpu_usage", "%"),
+		}),
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the service be stopped?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  extutil.Ptr("*Stop:* Stop the unit, systemd doesn't restart it but it may be started again by dependencies or socket activation.\n\n*Kill:* Send a signal to the processes of the unit, systemd restarts it according to its Restart policy.\n\n*Mask:* Mask and stop the unit, so it can't be started by anything until it is unmasked at the end."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(stopServiceModeStop),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(2),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Stop",
+						Value: stopServiceModeStop,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Kill",
+						Value: stopServiceModeKill,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Mask",
+						Value: stopServiceModeMask,
+					},
+				}),
+			},
+			{
+				Name:         "signal",
+				Label:        "Signal",
+				Description:  extutil.Ptr("Signal sent by mode 'Kill', e.g. SIGKILL or SIGTERM."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr("SIGKILL"),
+				Required:     extutil.Ptr(false),
+				Advanced:     extutil.Ptr(true),
+				Order:        extutil.Ptr(3),
+			},
+		},
+		Status: extutil.Ptr(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: extutil.Ptr("1s"),
+		}),
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *stopServiceAction) Prepare(ctx context.Context, state *StopServiceActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	if names := request.Target.Attributes["host.service.name"]; len(names) > 0 {
+		state.Unit = names[0]
+	}
+	state.Mode = extutil.ToString(request.Config["mode"])
+
+	var refusal error
+	switch state.Mode {
+	case stopServiceModeStop, stopServiceModeMask:
+	case stopServiceModeKill:
+		signal, err := stopprocess.ParseSignal(extutil.ToString(request.Config["signal"]))
+		if err != nil {
+			refusal = err
+		} else {
+			state.Signal = unix.SignalName(signal)
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode %s", state.Mode)
+	}
+
+	if refusal == nil {
+		refusal = a.prepareUnit(ctx, state)
+	}
+	if refusal != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Cannot stop service",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(refusal.Error()),
+			}),
+		}, nil
+	}
+	return nil, nil
+}
+
+// prepareUnit records the state and restart policy of the unit and refuses units which can't be stopped
+func (a *stopServiceAction) prepareUnit(ctx context.Context, state *StopServiceActionState) error {
+	if state.Unit == "" {
+		return errors.New("target is missing the 'host.service.name' attribute")
+	}
+	if slices.Contains(protectedServices, state.Unit) {
+		return fmt.Errorf("unit %s is needed by the extension or the agent and can't be stopped", state.Unit)
+	}
+	ancestors, err := extensionServices(ctx)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(ancestors, state.Unit) {
+		return fmt.Errorf("unit %s runs the extension and can't be stopped", state.Unit)
+	}
+	props, err := systemd.Show(ctx, state.Unit, "LoadState", "ActiveState", "Restart", "ControlGroup")
+	if err != nil {
+		return fmt.Errorf("failed to read unit %s: %w", state.Unit, err)
+	}
+	if props["LoadState"] != "loaded" {
+		return fmt.Errorf("unit %s is %s", state.Unit, props["LoadState"])
+	}
+	if props["ActiveState"] != "active" {
+		return fmt.Errorf("unit %s is %s, only active units can be stopped", state.Unit, props["ActiveState"])
+	}
+	if props["ControlGroup"] != "" {
+		own, err := cgroup.ProcessCgroup(ctx, os.Getpid(), "systemd")
+		if err != nil {
+			return fmt.Errorf("failed to read the cgroup of the extension: %w", err)
+		}
+		if own.Path == props["ControlGroup"] || strings.HasPrefix(own.Path, props["ControlGroup"]+"/") {
+			return fmt.Errorf("unit %s runs the extension and can't be stopped", state.Unit)
+		}
+	}
+	state.LastState = props["ActiveState"]
+	state.Restart = props["Restart"]
+	return nil
+}
+
+// extensionServices returns the services of the extension and its ancestor processes, e.g. of the container runtime running it.
+var extensionServices = func(ctx context.Context) ([]string, error) {
+	var result []string
+	for pid := os.Getpid(); pid > 1; {
+		c, err := cgroup.ProcessCgroup(ctx, pid, "systemd")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the cgroup of the extension: %w", err)
+		}
+		if unit := serviceOfCgroup(c.Path); unit != "" && !slices.Contains(result, unit) {
+			result = append(result, unit)
+		}
+		p, err := ps.FindProcess(pid)
+		if err != nil || p == nil {
+			break
+		}
+		pid = p.PPid()
+	}
+	return result, nil
+}
+
+// serviceOfCgroup returns the system service of the cgroup, services of user managers below it are ignored.
+func serviceOfCgroup(path string) string {
+	for _, name := range strings.Split(path, "/") {
+		if strings.HasSuffix(name, ".service") {
+			return name
+		}
+	}
+	return ""
+}
+
+func (a *stopServiceAction) Start(ctx context.Context, state *StopServiceActionState) (*action_kit_api.StartResult, error) {
+	var err error
+	switch state.Mode {
+	case stopServiceModeKill:
+		state.Applied = true
+		err = systemd.KillUnit(ctx, state.Unit, state.Signal)
+	case stopServiceModeMask:
+		state.Masked = true
+		if err = systemd.MaskUnit(ctx, state.Unit); err == nil {
+			state.Applied = true
+			err = systemd.StopUnit(ctx, state.Unit)
+		}
+	default:
+		state.Applied = true
+		err = systemd.StopUnit(ctx, state.Unit)
+	}
+	if err != nil {
+		if _, stopErr := a.Stop(ctx, state); stopErr != nil {
+			log.Error().Err(stopErr).Msg("Failed to revert stop service")
+		}
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to %s unit %s", strings.ToLower(state.Mode), state.Unit), err)
+	}
+
+	messages := []action_kit_api.Message{}
+	if state.Mode == stopServiceModeKill {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Sent %s to unit %s, its restart policy is Restart=%s", state.Signal, state.Unit, state.Restart),
+		})
+	}
+	if message := a.observeState(ctx, state); message != nil {
+		messages = append(messages, *message)
+	}
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr(messages),
+		Metrics:  extutil.Ptr(a.sampleUsage(ctx, state, time.Now())),
+	}, nil
+}
+
+func (a *stopServiceAction) Status(ctx context.Context, state *StopServiceActionState) (*action_kit_api.StatusResult, error) {
+	var messages []action_kit_api.Message
+	if message := a.observeState(ctx, state); message != nil {
+		messages = append(messages, *message)
+	}
+	return &action_kit_api.StatusResult{
+		Completed: false,
+		Messages:  extutil.Ptr(messages),
+		Metrics:   extutil.Ptr(a.sampleUsage(ctx, state, time.Now())),
+	}, nil
+}
+
+// sampleUsage returns the memory and CPU usage of the unit. There are no metrics while the unit isn't running or has accounting disabled.
+func (a *stopServiceAction) sampleUsage(ctx context.Context, state *StopServiceActionState, now time.Time) []action_kit_api.Metric {
+	usage, err := readServiceUsage(ctx, state.Unit)
+	if err != nil {
+		log.Warn().Err(err).Str("unit", state.Unit).Msg("Failed to read the usage of the unit")
+		return []action_kit_api.Metric{}
+	}
+	return serviceUsageMetrics(state, usage, now)
+}
+
+// serviceUsageMetrics converts the usage to metrics. The CPU usage is the share of one CPU since the previous sample, the
+// counter restarts with the unit, so a decreasing counter starts a new sample.
+func serviceUsageMetrics(state *StopServiceActionState, usage systemd.Usage, now time.Time) []action_kit_api.Metric {
+	metrics := []action_kit_api.Metric{}
+	if usage.MemoryCurrent != nil {
+		metrics = append(metrics, serviceUsageMetric("service_memory_usage", state.Unit, float64(*usage.MemoryCurrent)/bytesPerMegabyte, now))
+	}
+	if usage.CPUUsageNSec == nil {
+		state.CPUUsageNSec, state.UsageSampledAt = 0, time.Time{}
+		return metrics
+	}
+	if !state.UsageSampledAt.IsZero() && *usage.CPUUsageNSec >= state.CPUUsageNSec && now.After(state.UsageSampledAt) {
+		share := float64(*usage.CPUUsageNSec-state.CPUUsageNSec) / float64(now.Sub(state.UsageSampledAt).Nanoseconds())
+		metrics = append(metrics, serviceUsageMetric("service_cpu_usage", state.Unit, share*100, now))
+	}
+	state.CPUUsageNSec, state.UsageSampledAt = *usage.CPUUsageNSec, now
+	return metrics
+}
+
+func serviceUsageMetric(name, unit string, value float64, now time.Time) action_kit_api.Metric {
+	return action_kit_api.Metric{
+		Name: extutil.Ptr(name),
+		Metric: map[string]string{
+			"service": unit,
+		},
+		Value:     value,
+		Timestamp: now,
+	}
+}
+
+func serviceUsageWidget(title, metricName, unit string) action_kit_api.LineChartWidget {
+	return action_kit_api.LineChartWidget{
+		Type:  action_kit_api.ComSteadybitWidgetLineChart,
+		Title: title,
+		Identity: action_kit_api.LineChartWidgetIdentityConfig{
+			MetricName: metricName,
+			From:       "service",
+			Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeSelect,
+		},
+		Tooltip: extutil.Ptr(action_kit_api.LineChartWidgetTooltipConfig{
+			MetricValueTitle: extutil.Ptr("Usage"),
+			MetricValueUnit:  extutil.Ptr(unit),
+			AdditionalContent: []action_kit_api.LineChartWidgetTooltipContent{
+				{
+					From:  "service",
+					Title: "Service",
+				},
+			},
+		}),
+	}
+}
+
+// observeState returns a message if the ActiveState of the unit changed since it was last observed
+func (a *stopServiceAction) observeState(ctx context.Context, state *StopServiceActionState) *action_kit_api.Message {
+	activeState, err := systemd.ActiveState(ctx, state.Unit)
+	if err != nil {
+		log.Warn().Err(err).Str("unit", state.Unit).Msg("Failed to read the state of the unit")
+		return nil
+	}
+	if activeState == state.LastState {
+		return nil
+	}
+	message := &action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Unit %s changed from %s to %s", state.Unit, state.LastState, activeState),
+	}
+	state.LastState = activeState
+	return message
+}
+
+func (a *stopServiceAction) Stop(ctx context.Context, state *StopServiceActionState) (*action_kit_api.StopResult, error) {
+	if !state.Applied && !state.Masked {
+		log.Debug().Msg("No service stopped, skipping revert")
+		return nil, nil
+	}
+
+	var errs []error
+	if state.Masked {
+		if err := systemd.UnmaskUnit(ctx, state.Unit); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.Masked = false
+		}
+	}
+	if state.Applied && !state.Masked {
+		// systemd may already have restarted the unit, e.g. after a kill with Restart=always
+		if activeState, err := systemd.ActiveState(ctx, state.Unit); err != nil {
+			errs = append(errs, err)
+		} else if activeState == "active" || activeState == "activating" || activeState == "reloading" {
+			state.Applied = false
+		} else if err := systemd.StartUnit(ctx, state.Unit); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.Applied = false
+		}
+	}
+
+	var messages []action_kit_api.Message
+	if message := a.observeState(ctx, state); message != nil {
+		messages = append(messages, *message)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to start unit %s", state.Unit), err)
+	}
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr(messages),
+	}, nil
+}
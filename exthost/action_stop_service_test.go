@@ -0,0 +1,142 @@
+package exthost
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-host/exthost/systemd"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestActionStopService_Prepare(t *testing.T) {
+	osHostname = func() (string, error) {
+		return "myhostname", nil
+	}
+	originalExtensionServices := extensionServices
+	extensionServices = func(context.Context) ([]string, error) {
+		return []string{"steadybit-extension-host.service", "my-runtime.service"}, nil
+	}
+	t.Cleanup(func() { extensionServices = originalExtensionServices })
+	tests := []struct {
+		name         string
+		service      string
+		config       map[string]interface{}
+		wantedError  string
+		wantedDetail string
+	}{
+		{
+			name: "Should require service name",
+			config: map[string]interface{}{
+				"duration": "10000",
+				"mode":     "STOP",
+			},
+			wantedDetail: "target is missing the 'host.service.name' attribute",
+		}, {
+			name:    "Should reject unknown signal",
+			service: "nginx.service",
+			config: map[string]interface{}{
+				"duration": "10000",
+				"mode":     "KILL",
+				"signal":   "SIGFOO",
+			},
+			wantedDetail: "unknown signal SIGFOO",
+		}, {
+			name:    "Should reject invalid mode",
+			service: "nginx.service",
+			config: map[string]interface{}{
+				"duration": "10000",
+				"mode":     "RESTART",
+			},
+			wantedError: "invalid mode RESTART",
+		}, {
+			name:    "Should refuse the container runtime",
+			service: "containerd.service",
+			config: map[string]interface{}{
+				"duration": "10000",
+				"mode":     "MASK",
+			},
+			wantedDetail: "unit containerd.service is needed by the extension or the agent and can't be stopped",
+		}, {
+			name:    "Should refuse the service of an ancestor of the extension",
+			service: "my-runtime.service",
+			config: map[string]interface{}{
+				"duration": "10000",
+				"mode":     "STOP",
+			},
+			wantedDetail: "unit my-runtime.service runs the extension and can't be stopped",
+		},
+	}
+	action := NewStopServiceAction()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := StopServiceActionState{}
+			attributes := map[string][]string{
+				"host.hostname": {"myhostname"},
+			}
+			if tt.service != "" {
+				attributes["host.service.name"] = []string{tt.service}
+			}
+			request := action_kit_api.PrepareActionRequestBody{
+				Config:      tt.config,
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: attributes,
+				}),
+			}
+
+			result, err := action.Prepare(context.Background(), &state, request)
+
+			if tt.wantedError != "" {
+				assert.EqualError(t, err, tt.wantedError)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			require.NotNil(t, result.Error)
+			assert.Equal(t, "Cannot stop service", result.Error.Title)
+			assert.Equal(t, tt.wantedDetail, *result.Error.Detail)
+		})
+	}
+}
+
+func TestActionStopService_StopWithoutStart(t *testing.T) {
+	state := StopServiceActionState{Unit: "nginx.service", Mode: stopServiceModeStop}
+	result, err := NewStopServiceAction().(*stopServiceAction).Stop(context.Background(), &state)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func Test_serviceOfCgroup(t *testing.T) {
+	assert.Equal(t, "containerd.service", serviceOfCgroup("/system.slice/containerd.service"))
+	assert.Equal(t, "user@1000.service", serviceOfCgroup("/user.slice/user-1000.slice/user@1000.service/app.slice/app.service"))
+	assert.Equal(t, "", serviceOfCgroup("/system.slice/docker-0123abcd.scope"))
+	assert.Equal(t, "", serviceOfCgroup("/"))
+}
+
+func Test_serviceUsageMetrics(t *testing.T) {
+	state := StopServiceActionState{Unit: "nginx.service"}
+	now := time.Now()
+
+	metrics := serviceUsageMetrics(&state, systemd.Usage{MemoryCurrent: extutil.Ptr(uint64(2 * bytesPerMegabyte)), CPUUsageNSec: extutil.Ptr(uint64(1_000_000_000))}, now)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "service_memory_usage", *metrics[0].Name)
+	assert.Equal(t, map[string]string{"service": "nginx.service"}, metrics[0].Metric)
+	assert.InDelta(t, 2, metrics[0].Value, 0.001)
+
+	metrics = serviceUsageMetrics(&state, systemd.Usage{CPUUsageNSec: extutil.Ptr(uint64(1_500_000_000))}, now.Add(time.Second))
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "service_cpu_usage", *metrics[0].Name)
+	assert.InDelta(t, 50, metrics[0].Value, 0.001)
+
+	// the counter restarts with the unit
+	metrics = serviceUsageMetrics(&state, systemd.Usage{CPUUsageNSec: extutil.Ptr(uint64(100))}, now.Add(2*time.Second))
+	assert.Empty(t, metrics)
+
+	metrics = serviceUsageMetrics(&state, systemd.Usage{}, now.Add(3*time.Second))
+	assert.Empty(t, metrics)
+	assert.True(t, state.UsageSampledAt.IsZero())
+}
@@ -0,0 +1,400 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-host/exthost/systemd"
+	"github.com/steadybit/extension-host/exthost/timetravel"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	timeNamespaceModeCommand = "COMMAND"
+	timeNamespaceModeUnit    = "UNIT"
+)
+
+type timeNamespaceAction struct {
+	service bool
+}
+
+type TimeNamespaceActionState struct {
+	Mode          string
+	Unit          string
+	TransientUnit string
+	Offset        time.Duration
+	OffsetSeconds int64
+	Command       []string
+	// DropIn overrides the ExecStart of the unit, DropInWritten is set once it may exist
+	DropIn        string
+	DropInWritten bool
+	Started       bool
+}
+
+// timeNamespaceDropIn is the name of the drop-in, it sorts after drop-ins of the administrator like override.conf
+const timeNamespaceDropIn = "zz-steadybit-time-namespace"
+
+// Make sure action implements all required interfaces
+var (
+	_ action_kit_sdk.Action[TimeNamespaceActionState]           = (*timeNamespaceAction)(nil)
+	_ action_kit_sdk.ActionWithStop[TimeNamespaceActionState]   = (*timeNamespaceAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[TimeNamespaceActionState] = (*timeNamespaceAction)(nil)
+)
+
+func NewTimeNamespaceAction() action_kit_sdk.Action[TimeNamespaceActionState] {
+	return &timeNamespaceAction{}
+}
+
+// NewTimeNamespaceServiceAction restarts a discovered systemd service in a time namespace
+func NewTimeNamespaceServiceAction() action_kit_sdk.Action[TimeNamespaceActionState] {
+	return &timeNamespaceAction{service: true}
+}
+
+func (a *timeNamespaceAction) NewEmptyState() TimeNamespaceActionState {
+	return TimeNamespaceActionState{}
+}
+
+func (a *timeNamespaceAction) Describe() action_kit_api.ActionDescription {
+	d := action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.time-namespace", BaseActionID),
+		Label:       "Time Travel Process",
+		Description: "Runs a workload in a time namespace with shifted monotonic and boot time clocks, the clocks of the host and other processes are not changed. The wall clock can't be shifted by a time namespace.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        extutil.Ptr(timeTravelIcon),
+		TargetSelection: &action_kit_api.TargetSelection{
+			TargetType:         targetID,
+			SelectionTemplates: &targetSelectionTemplates,
+		},
+		Technology:  extutil.Ptr("Linux Host"),
+		Category:    extutil.Ptr("State"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  extutil.Ptr("How long should the workload run in the time namespace?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: extutil.Ptr("30s"),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(1),
+			},
+			{
+				Name:         "mode",
+				Label:        "Workload",
+				Description:  extutil.Ptr("*Command:* Launch the command in the time namespace, it is stopped at the end.\n\n*Systemd Unit:* Restart the service with a runtime drop-in running its ExecStart in the time namespace as the unit's user, the drop-in is removed and the service restarted again at the end. The sandboxing of the unit is not applied while it runs in the time namespace."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(timeNamespaceModeCommand),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(2),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Command",
+						Value: timeNamespaceModeCommand,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Systemd Unit",
+						Value: timeNamespaceModeUnit,
+					},
+				}),
+			},
+			{
+				Name:        "command",
+				Label:       "Command",
+				Description: extutil.Ptr("The shell command to launch. Only used by workload Command."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(3),
+			},
+			{
+				Name:        "unit",
+				Label:       "Systemd Unit",
+				Description: extutil.Ptr("The service to restart in the time namespace, e.g. nginx.service. Only used by workload Systemd Unit."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    extutil.Ptr(false),
+				Order:       extutil.Ptr(4),
+			},
+			{
+				Name:          "offset",
+				Label:         "Offset",
+				Description:   extutil.Ptr("The offset of the monotonic and boot time clocks, in whole seconds."),
+				Type:          action_kit_api.ActionParameterTypeDuration,
+				DurationUnits: extutil.Ptr([]action_kit_api.DurationUnit{action_kit_api.DurationUnitSeconds, action_kit_api.DurationUnitMinutes, action_kit_api.DurationUnitHours, action_kit_api.DurationUnitDays}),
+				DefaultValue:  extutil.Ptr("60m"),
+				Required:      extutil.Ptr(true),
+				Order:         extutil.Ptr(5),
+			},
+			{
+				Name:         "direction",
+				Label:        "Direction",
+				Description:  extutil.Ptr("Whether to shift the clocks into the future or the past. Shifting into the past is limited by the uptime of the host."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(timeTravelDirectionForward),
+				Required:     extutil.Ptr(false),
+				Order:        extutil.Ptr(6),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Forward",
+						Value: timeTravelDirectionForward,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Backward",
+						Value: timeTravelDirectionBackward,
+					},
+				}),
+			},
+		},
+		Stop: extutil.Ptr(action_kit_api.MutatingEndpointReference{}),
+	}
+	if a.service {
+		d = serviceActionDescription(d, "mode", "command", "unit")
+		d.Label = "Time Travel Service"
+		d.Description = "Restarts the systemd service in a time namespace with shifted monotonic and boot time clocks, the clocks of the host and other processes are not changed. The wall clock can't be shifted by a time namespace."
+	}
+	return d
+}
+
+func (a *timeNamespaceAction) Prepare(ctx context.Context, state *TimeNamespaceActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if _, err := CheckTargetHostname(request.Target.Attributes); err != nil {
+		return nil, err
+	}
+
+	if !timetravel.NamespaceSupported() {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Time namespaces are not supported by the kernel",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr("Time namespaces require Linux 5.6 or later."),
+			}),
+		}, nil
+	}
+
+	offset, err := timeTravelOffset(request.Config)
+	if err == nil {
+		state.OffsetSeconds, err = timetravel.NamespaceOffset(offset)
+	}
+	if err != nil {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Invalid offset",
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
+			}),
+		}, nil
+	}
+	state.Offset = offset
+
+	state.Mode = extutil.ToString(request.Config["mode"])
+	unit := strings.TrimSpace(extutil.ToString(request.Config["unit"]))
+	if a.service {
+		state.Mode = timeNamespaceModeUnit
+		if names := request.Target.Attributes["host.service.name"]; len(names) > 0 {
+			unit = names[0]
+		}
+	}
+	switch state.Mode {
+	case timeNamespaceModeCommand:
+		command := strings.TrimSpace(extutil.ToString(request.Config["command"]))
+		if command == "" {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Command is required",
+					Status: extutil.Ptr(action_kit_api.Errored),
+				}),
+			}, nil
+		}
+		state.Command = timetravel.NamespaceCommand(state.OffsetSeconds, "", "", []string{"sh", "-c", command})
+	case timeNamespaceModeUnit:
+		state.Unit = unit
+		if state.Unit == "" {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Systemd unit is required",
+					Status: extutil.Ptr(action_kit_api.Errored),
+				}),
+			}, nil
+		}
+		dropIn, err := timeNamespaceUnitDropIn(ctx, state.Unit, state.OffsetSeconds)
+		if err != nil {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  fmt.Sprintf("Cannot run %s in a time namespace", state.Unit),
+					Status: extutil.Ptr(action_kit_api.Errored),
+					Detail: extutil.Ptr(err.Error()),
+				}),
+			}, nil
+		}
+		state.DropIn = dropIn
+	default:
+		return nil, fmt.Errorf("invalid mode %s", state.Mode)
+	}
+
+	if state.Mode == timeNamespaceModeCommand {
+		state.TransientUnit = fmt.Sprintf("steadybit-time-namespace-%s.service", request.ExecutionId)
+	}
+	return nil, nil
+}
+
+// timeNamespaceUnitDropIn returns the drop-in running the ExecStart of the running service in a time namespace as the unit's user
+func timeNamespaceUnitDropIn(ctx context.Context, unit string, offsetSeconds int64) (string, error) {
+	props, err := systemd.Show(ctx, unit, "ActiveState", "User", "Group")
+	if err != nil {
+		return "", err
+	}
+	if props["ActiveState"] != "active" {
+		return "", fmt.Errorf("unit %s is %s, only running services can be restarted in a time namespace", unit, props["ActiveState"])
+	}
+	execStart, err := systemd.ExecStartLine(ctx, unit)
+	if err != nil {
+		return "", err
+	}
+	return timetravel.NamespaceDropIn(execStart, offsetSeconds, props["User"], props["Group"])
+}
+
+// workloadUnit returns the unit running in the time namespace
+func (s *TimeNamespaceActionState) workloadUnit() string {
+	if s.Mode == timeNamespaceModeUnit {
+		return s.Unit
+	}
+	return s.TransientUnit
+}
+
+func (a *timeNamespaceAction) Start(ctx context.Context, state *TimeNamespaceActionState) (*action_kit_api.StartResult, error) {
+	if state.Mode == timeNamespaceModeUnit {
+		log.Info().Str("unit", state.Unit).Str("dropIn", state.DropIn).Msg("Restarting unit in time namespace")
+		state.DropInWritten = true
+		err := systemd.WriteRuntimeDropIn(ctx, state.Unit, timeNamespaceDropIn, state.DropIn)
+		if err == nil {
+			err = systemd.DaemonReload(ctx)
+		}
+		if err == nil {
+			err = systemd.RestartUnit(ctx, state.Unit)
+		}
+		if err != nil {
+			if _, stopErr := a.Stop(context.Background(), state); stopErr != nil {
+				log.Error().Err(stopErr).Str("unit", state.Unit).Msg("Failed to restore unit")
+			}
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to restart %s in a time namespace", state.Unit), err)
+		}
+	} else {
+		log.Info().Strs("command", state.Command).Str("unit", state.TransientUnit).Msg("Starting workload in time namespace")
+		if err := systemd.RunTransient(ctx, systemd.TransientService{Unit: state.TransientUnit}, state.Command...); err != nil {
+			return nil, extension_kit.ToError("Failed to start the workload in a time namespace", err)
+		}
+	}
+	state.Started = true
+
+	message := fmt.Sprintf("Running the command in a time namespace with clocks shifted by %s as %s", time.Duration(state.OffsetSeconds)*time.Second, state.TransientUnit)
+	if state.Mode == timeNamespaceModeUnit {
+		message = fmt.Sprintf("Restarted %s in a time namespace with clocks shifted by %s", state.Unit, time.Duration(state.OffsetSeconds)*time.Second)
+	}
+	return &action_kit_api.StartResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: message,
+			},
+		}),
+	}, nil
+}
+
+func (a *timeNamespaceAction) Status(ctx context.Context, state *TimeNamespaceActionState) (*action_kit_api.StatusResult, error) {
+	if !state.Started {
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+
+	unit := state.workloadUnit()
+	props, err := systemd.Show(ctx, unit, "ActiveState", "Result", "ExecMainStatus")
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to read the state of %s", unit), err)
+	}
+	switch props["ActiveState"] {
+	case "inactive":
+		// a transient unit which exited successfully is garbage collected and inactive from then on
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	case "failed":
+		return &action_kit_api.StatusResult{
+			Completed: true,
+			Error: &action_kit_api.ActionKitError{
+				Status: extutil.Ptr(action_kit_api.Failed),
+				Title:  fmt.Sprintf("The workload in the time namespace failed with result %s and exit status %s, see journalctl -u %s", props["Result"], props["ExecMainStatus"], unit),
+			},
+		}, nil
+	default:
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+}
+
+func (a *timeNamespaceAction) Stop(ctx context.Context, state *TimeNamespaceActionState) (*action_kit_api.StopResult, error) {
+	if !state.Started && !state.DropInWritten {
+		log.Debug().Msg("No workload started in a time namespace, skipping revert")
+		return nil, nil
+	}
+
+	var errs []error
+	if state.Mode == timeNamespaceModeUnit {
+		err := systemd.RemoveRuntimeDropIn(ctx, state.Unit, timeNamespaceDropIn)
+		if err == nil {
+			err = systemd.DaemonReload(ctx)
+		}
+		if err == nil {
+			err = systemd.RestartUnit(ctx, state.Unit)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			state.DropInWritten = false
+			state.Started = false
+		}
+	} else if state.Started {
+		if activeState, err := systemd.ActiveState(ctx, state.TransientUnit); err != nil {
+			errs = append(errs, err)
+		} else if activeState == "inactive" {
+			state.Started = false
+		} else if activeState == "failed" {
+			// failed transient units are kept to report their result
+			if err := systemd.ResetFailed(ctx, state.TransientUnit); err != nil {
+				errs = append(errs, err)
+			} else {
+				state.Started = false
+			}
+		} else if err := systemd.StopUnit(ctx, state.TransientUnit); err != nil {
+			errs = append(errs, err)
+		} else {
+			// a command not exiting cleanly on SIGTERM is kept as failed
+			if activeState, err := systemd.ActiveState(ctx, state.TransientUnit); err == nil && activeState == "failed" {
+				if err := systemd.ResetFailed(ctx, state.TransientUnit); err != nil {
+					log.Warn().Err(err).Str("unit", state.TransientUnit).Msg("Failed to unload the failed transient unit")
+				}
+			}
+			state.Started = false
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, extension_kit.ToError("Failed to stop the workload in the time namespace", err)
+	}
+
+	message := "Stopped the workload in the time namespace"
+	if state.Mode == timeNamespaceModeUnit {
+		message = fmt.Sprintf("Removed the time namespace from %s and restarted it", state.Unit)
+	}
+	return &action_kit_api.StopResult{
+		Messages: extutil.Ptr([]action_kit_api.Message{
+			{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: message,
+			},
+		}),
+	}, nil
+}
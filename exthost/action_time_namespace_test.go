@@ -0,0 +1,90 @@
+package exthost
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-host/exthost/timetravel"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestActionTimeNamespace_Prepare(t *testing.T) {
+	if !timetravel.NamespaceSupported() {
+		t.Skip("time namespaces not supported")
+	}
+	osHostname = func() (string, error) {
+		return "myhostname", nil
+	}
+	executionId := uuid.New()
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		wantedError string
+		wantedState *TimeNamespaceActionState
+	}{
+		{
+			name: "Should run command in time namespace",
+			config: map[string]interface{}{
+				"duration":  "10000",
+				"mode":      "COMMAND",
+				"command":   "sleep infinity",
+				"offset":    "3600000",
+				"direction": "FORWARD",
+			},
+			wantedState: &TimeNamespaceActionState{
+				Mode:          timeNamespaceModeCommand,
+				TransientUnit: "steadybit-time-namespace-" + executionId.String() + ".service",
+				Offset:        time.Hour,
+				OffsetSeconds: 3600,
+				Command:       []string{"unshare", "--time", "--fork", "--monotonic", "3600", "--boottime", "3600", "--", "sh", "-c", "sleep infinity"},
+			},
+		},
+		{
+			name: "Should return error for missing command",
+			config: map[string]interface{}{
+				"duration": "10000",
+				"mode":     "COMMAND",
+				"offset":   "3600000",
+			},
+			wantedError: "Command is required",
+		},
+		{
+			name: "Should return error for sub second offset",
+			config: map[string]interface{}{
+				"duration": "10000",
+				"mode":     "COMMAND",
+				"command":  "date",
+				"offset":   "500",
+			},
+			wantedError: "Invalid offset",
+		},
+	}
+	action := NewTimeNamespaceAction()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := action.NewEmptyState()
+			result, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+				Config:      tt.config,
+				ExecutionId: executionId,
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			})
+			require.NoError(t, err)
+
+			if tt.wantedError != "" {
+				require.NotNil(t, result)
+				assert.Equal(t, tt.wantedError, result.Error.Title)
+			} else {
+				assert.Nil(t, result)
+				assert.Equal(t, *tt.wantedState, state)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ package exthost
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_commons/network"
@@ -15,6 +17,8 @@ import (
 	"github.com/steadybit/extension-host/exthost/timetravel"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
+	"golang.org/x/sys/unix"
+	"strings"
 	"time"
 )
 
@@ -23,11 +27,28 @@ type timeTravelAction struct {
 }
 
 type TimeTravelActionState struct {
+	Mode          string
 	DisableNtp    bool
 	Offset        time.Duration
+	DriftPpm      int64
 	OffsetApplied bool
+
+	NtpBlocked     bool
+	PausedTimeSync []timetravel.TimeSyncDaemon
+
+	OriginalAdjustment timetravel.ClockAdjustment
+	// Reference is taken before changing the clock, the correct time is restored from it on stop
+	Reference timetravel.Reference
 }
 
+const (
+	timeTravelModeJump  = "JUMP"
+	timeTravelModeDrift = "DRIFT"
+
+	timeTravelDirectionForward  = "FORWARD"
+	timeTravelDirectionBackward = "BACKWARD"
+)
+
 // Make sure action implements all required interfaces
 var (
 	_ action_kit_sdk.Action[TimeTravelActionState]         = (*timeTravelAction)(nil)
@@ -47,7 +68,7 @@ func (a *timeTravelAction) Describe() action_kit_api.ActionDescription {
 	return action_kit_api.ActionDescription{
 		Id:          timeTravelActionID,
 		Label:       "Time Travel",
-		Description: "Change the system time by the given offset.",
+		Description: "Change the system time by the given offset or let the clock drift at the given rate.",
 		Version:     extbuild.GetSemverVersionStringOrUnknown(),
 		Icon:        extutil.Ptr(timeTravelIcon),
 		TargetSelection: extutil.Ptr(action_kit_api.TargetSelection{
@@ -76,16 +97,54 @@ func (a *timeTravelAction) Describe() action_kit_api.ActionDescription {
 
 		// The parameters for the action
 		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  extutil.Ptr("*Jump:* Set the clock to the current time plus the offset at once.\n\n*Drift:* Let the clock run fast or slow at the given drift rate and step it back to the correct time at the end."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(timeTravelModeJump),
+				Required:     extutil.Ptr(true),
+				Order:        extutil.Ptr(0),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Jump",
+						Value: timeTravelModeJump,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Drift",
+						Value: timeTravelModeDrift,
+					},
+				}),
+			},
 			{
 				Name:          "offset",
 				Label:         "Offset",
-				Description:   extutil.Ptr("The offset to the current time."),
+				Description:   extutil.Ptr("The offset to the current time. Only used by mode Jump."),
 				Type:          action_kit_api.ActionParameterTypeDuration,
 				DurationUnits: extutil.Ptr([]action_kit_api.DurationUnit{action_kit_api.DurationUnitMilliseconds, action_kit_api.DurationUnitSeconds, action_kit_api.DurationUnitMinutes, action_kit_api.DurationUnitHours, action_kit_api.DurationUnitDays}),
 				DefaultValue:  extutil.Ptr("60m"),
 				Required:      extutil.Ptr(true),
 				Order:         extutil.Ptr(1),
 			},
+			{
+				Name:         "direction",
+				Label:        "Direction",
+				Description:  extutil.Ptr("Whether to travel into the future or the past. Only used by mode Jump."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: extutil.Ptr(timeTravelDirectionForward),
+				Required:     extutil.Ptr(false),
+				Order:        extutil.Ptr(2),
+				Options: extutil.Ptr([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Forward",
+						Value: timeTravelDirectionForward,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Backward",
+						Value: timeTravelDirectionBackward,
+					},
+				}),
+			},
 			{
 				Name:         "duration",
 				Label:        "Duration",
@@ -93,11 +152,21 @@ func (a *timeTravelAction) Describe() action_kit_api.ActionDescription {
 				Type:         action_kit_api.ActionParameterTypeDuration,
 				DefaultValue: extutil.Ptr("30s"),
 				Required:     extutil.Ptr(true),
-				Order:        extutil.Ptr(2),
+				Order:        extutil.Ptr(3),
+			}, {
+				Name:         "driftRate",
+				Label:        "Drift Rate (ppm)",
+				Description:  extutil.Ptr("How fast (positive) or slow (negative) the clock runs in parts per million, e.g. 1000 ppm drifts by 60ms per minute. Between -100000 and 100000 (6s per minute). Only used by mode Drift."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: extutil.Ptr("1000"),
+				Required:     extutil.Ptr(false),
+				MinValue:     extutil.Ptr(-100_000),
+				MaxValue:     extutil.Ptr(100_000),
+				Order:        extutil.Ptr(4),
 			}, {
 				Name:         "disableNtp",
 				Label:        "Disable NTP",
-				Description:  extutil.Ptr("Prevent NTP from correcting time during attack."),
+				Description:  extutil.Ptr("Prevent time synchronization from correcting time during attack. Blocks NTP, NTS and PTP traffic and pauses chronyd, systemd-timesyncd and ntpd."),
 				Type:         action_kit_api.ActionParameterTypeBoolean,
 				DefaultValue: extutil.Ptr("true"),
 				Required:     extutil.Ptr(false),
@@ -121,46 +190,161 @@ func (a *timeTravelAction) Prepare(_ context.Context, state *TimeTravelActionSta
 		return nil, err
 	}
 
-	state.Offset = time.Duration(extutil.ToUInt64(request.Config["offset"])) * time.Millisecond
-	if state.Offset < 1*time.Second {
+	state.Mode = extutil.ToString(request.Config["mode"])
+	if state.Mode == "" {
+		state.Mode = timeTravelModeJump
+	}
+	state.DisableNtp = extutil.ToBool(request.Config["disableNtp"])
+
+	switch state.Mode {
+	case timeTravelModeJump:
+	case timeTravelModeDrift:
+		state.DriftPpm = extutil.ToInt64(request.Config["driftRate"])
+		if state.DriftPpm == 0 {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Drift rate must not be 0",
+					Status: extutil.Ptr(action_kit_api.Errored),
+				}),
+			}, nil
+		}
+		if _, err := timetravel.DriftAdjustment(timetravel.ClockAdjustment{Tick: 10_000}, state.DriftPpm); err != nil {
+			return &action_kit_api.PrepareResult{
+				Error: extutil.Ptr(action_kit_api.ActionKitError{
+					Title:  "Drift rate out of range",
+					Status: extutil.Ptr(action_kit_api.Errored),
+					Detail: extutil.Ptr(err.Error()),
+				}),
+			}, nil
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid mode %s", state.Mode)
+	}
+
+	offset, err := timeTravelOffset(request.Config)
+	if err != nil {
 		return &action_kit_api.PrepareResult{
 			Error: extutil.Ptr(action_kit_api.ActionKitError{
-				Title:  "Duration must be greater / equal than 1s",
+				Title:  "Invalid offset",
 				Status: extutil.Ptr(action_kit_api.Errored),
+				Detail: extutil.Ptr(err.Error()),
 			}),
 		}, nil
 	}
-	state.DisableNtp = extutil.ToBool(request.Config["disableNtp"])
+	if offset == 0 {
+		return &action_kit_api.PrepareResult{
+			Error: extutil.Ptr(action_kit_api.ActionKitError{
+				Title:  "Offset must not be 0",
+				Status: extutil.Ptr(action_kit_api.Errored),
+			}),
+		}, nil
+	}
+	state.Offset = offset
 
 	return nil, nil
 }
 
+// timeTravelOffset reads the signed offset in milliseconds, a backward direction negates it
+func timeTravelOffset(config map[string]interface{}) (time.Duration, error) {
+	offset := time.Duration(extutil.ToInt64(config["offset"])) * time.Millisecond
+	switch direction := extutil.ToString(config["direction"]); direction {
+	case "", timeTravelDirectionForward:
+		return offset, nil
+	case timeTravelDirectionBackward:
+		return -offset, nil
+	default:
+		return 0, fmt.Errorf("invalid direction %s", direction)
+	}
+}
+
 // Start is called to start the action
 // You can mutate the state here.
 // You can use the result to return messages/errors/metrics or artifacts
 func (a *timeTravelAction) Start(ctx context.Context, state *TimeTravelActionState) (*action_kit_api.StartResult, error) {
+	var messages []action_kit_api.Message
 	if state.DisableNtp {
-		log.Info().Msg("Blocking NTP traffic")
-		runner, err := a.runner(ctx)
+		message, err := a.disableTimeSync(ctx, state)
 		if err != nil {
-			log.Error().Err(err).Msg("Failed to create runner for blocking NTP traffic")
 			return nil, err
 		}
+		messages = append(messages, message)
+	}
 
-		if err := timetravel.AdjustNtpTrafficRules(ctx, runner, false); err != nil {
-			log.Error().Err(err).Msg("Failed to block ntp traffic")
+	if state.Mode == timeTravelModeDrift {
+		reference, err := timetravel.NewReference(unix.CLOCK_MONOTONIC_RAW)
+		if err != nil {
+			return nil, err
+		}
+		original, err := timetravel.StartDrift(state.DriftPpm)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to let the clock drift")
 			return nil, err
 		}
+		state.Reference = reference
+		state.OriginalAdjustment = original
+		state.OffsetApplied = true
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Letting the clock drift by %d ppm (%s per minute)", state.DriftPpm, time.Duration(state.DriftPpm)*time.Minute/1_000_000),
+		})
+		return &action_kit_api.StartResult{Messages: extutil.Ptr(messages)}, nil
 	}
 
+	reference, err := timetravel.NewReference(unix.CLOCK_MONOTONIC)
+	if err != nil {
+		return nil, err
+	}
+	state.Reference = reference
+
 	log.Info().Dur("offset", state.Offset).Msg("Adjusting time")
-	if err := timetravel.AdjustTime(state.Offset, false); err != nil {
+	applied, err := timetravel.AdjustTime(state.Offset)
+	// the clock may have been set even if verifying the offset failed, Stop must restore it then
+	state.OffsetApplied = applied
+	if err != nil {
 		log.Error().Err(err).Msg("Failed to adjust time")
 		return nil, err
 	}
 
-	state.OffsetApplied = true
-	return nil, nil
+	return &action_kit_api.StartResult{Messages: extutil.Ptr(messages)}, nil
+}
+
+// disableTimeSync blocks the traffic of time synchronization protocols and pauses the time sync daemons of the host
+func (a *timeTravelAction) disableTimeSync(ctx context.Context, state *TimeTravelActionState) (action_kit_api.Message, error) {
+	log.Info().Msg("Blocking NTP traffic")
+	runner, err := a.runner(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create runner for blocking NTP traffic")
+		return action_kit_api.Message{}, err
+	}
+	if err := timetravel.AdjustNtpTrafficRules(ctx, runner, false); err != nil {
+		log.Error().Err(err).Msg("Failed to block ntp traffic")
+		return action_kit_api.Message{}, err
+	}
+	state.NtpBlocked = true
+
+	daemons, err := timetravel.DetectTimeSyncDaemons()
+	if err != nil {
+		return action_kit_api.Message{}, err
+	}
+	state.PausedTimeSync, err = timetravel.PauseTimeSync(ctx, daemons)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to pause time sync daemons")
+		return action_kit_api.Message{}, err
+	}
+
+	message := "Blocked NTP, NTS and PTP traffic"
+	if len(state.PausedTimeSync) > 0 {
+		var names []string
+		for _, d := range state.PausedTimeSync {
+			names = append(names, d.String())
+		}
+		message = fmt.Sprintf("%s and paused %s", message, strings.Join(names, ", "))
+	}
+	return action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: message,
+	}, nil
 }
 
 // Stop is called to stop the action
@@ -168,32 +352,71 @@ func (a *timeTravelAction) Start(ctx context.Context, state *TimeTravelActionSta
 // It should be implemented in a immutable way, as the agent might to retries if the stop method timeouts.
 // You can use the result to return messages/errors/metrics or artifacts
 func (a *timeTravelAction) Stop(ctx context.Context, state *TimeTravelActionState) (*action_kit_api.StopResult, error) {
-	if !state.OffsetApplied {
+	if !state.OffsetApplied && !state.NtpBlocked && len(state.PausedTimeSync) == 0 {
 		log.Debug().Msgf("No offset applied, skipping revert")
 		return nil, nil
 	}
 
-	log.Info().Msg("Adjusting time back")
-	if state.DisableNtp {
-		log.Info().Msg("Unblocking NTP traffic")
-		runner, err := a.runner(ctx)
+	var messages []action_kit_api.Message
+	if state.OffsetApplied {
+		log.Info().Msg("Adjusting time back")
+		message, err := a.restoreTime(state)
 		if err != nil {
-			log.Error().Err(err).Msg("Failed to create runner for unblocking NTP traffic")
 			return nil, err
 		}
+		messages = append(messages, message)
+	}
 
-		if err := timetravel.AdjustNtpTrafficRules(ctx, runner, true); err != nil {
+	// time sync is enabled after restoring the time, so the daemons don't observe the offset
+	var errs []error
+	if len(state.PausedTimeSync) > 0 {
+		if err := timetravel.ResumeTimeSync(ctx, state.PausedTimeSync); err != nil {
+			log.Error().Err(err).Msg("Failed to resume time sync daemons")
+			errs = append(errs, err)
+		} else {
+			state.PausedTimeSync = nil
+		}
+	}
+	if state.NtpBlocked {
+		log.Info().Msg("Unblocking NTP traffic")
+		if runner, err := a.runner(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to create runner for unblocking NTP traffic")
+			errs = append(errs, err)
+		} else if err := timetravel.AdjustNtpTrafficRules(ctx, runner, true); err != nil {
 			log.Error().Err(err).Msg("Failed to unblock NTP traffic")
-			return nil, err
+			errs = append(errs, err)
+		} else {
+			state.NtpBlocked = false
 		}
 	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
 
-	if err := timetravel.AdjustTime(state.Offset, true); err != nil {
+	return &action_kit_api.StopResult{Messages: extutil.Ptr(messages)}, nil
+}
+
+// restoreTime resets the clock's frequency after drifting and steps the clock to the correct time of the reference
+func (a *timeTravelAction) restoreTime(state *TimeTravelActionState) (action_kit_api.Message, error) {
+	if state.Mode == timeTravelModeDrift {
+		if err := timetravel.SetClockAdjustment(state.OriginalAdjustment); err != nil {
+			log.Error().Err(err).Msg("Failed to reset clock frequency")
+			return action_kit_api.Message{}, err
+		}
+	}
+
+	step, residual, err := timetravel.RestoreTime(state.Reference)
+	if err != nil {
 		log.Error().Err(err).Msg("Failed to revert time adjustment")
-		return nil, err
+		return action_kit_api.Message{}, err
 	}
+	log.Info().Dur("step", step).Dur("residual", residual).Msg("Time restored")
+
 	state.OffsetApplied = false
-	return nil, nil
+	return action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Stepped the clock by %s to the correct time, residual error %s", step.Round(time.Millisecond), residual),
+	}, nil
 }
 
 func (a *timeTravelAction) runner(ctx context.Context) (network.CommandRunner, error) {
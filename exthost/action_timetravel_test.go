@@ -43,12 +43,107 @@ func TestActionTimeTravel_Prepare(t *testing.T) {
 				OffsetApplied: false,
 			},
 		}, {
-			name: "Should return error too low duration",
+			name: "Should return error for zero offset",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "1000",
+					"offset":   "0",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedError: "Offset must not be 0",
+		}, {
+			name: "Should allow sub second offset",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "1000",
+					"offset":   "999",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedState: &TimeTravelActionState{
+				Offset: 999 * time.Millisecond,
+			},
+		}, {
+			name: "Should negate offset travelling backward",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":    "prepare",
+					"duration":  "1000",
+					"offset":    "1500",
+					"direction": "BACKWARD",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedState: &TimeTravelActionState{
+				Offset: -1500 * time.Millisecond,
+			},
+		}, {
+			name: "Should allow negative offset",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":   "prepare",
+					"duration": "1000",
+					"offset":   -2000,
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedState: &TimeTravelActionState{
+				Offset: -2 * time.Second,
+			},
+		}, {
+			name: "Should return error for invalid direction",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":    "prepare",
+					"duration":  "1000",
+					"offset":    "1000",
+					"direction": "SIDEWAYS",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedError: "Invalid offset",
+		}, {
+			name: "Should return drift config",
 			requestBody: action_kit_api.PrepareActionRequestBody{
 				Config: map[string]interface{}{
 					"action":     "prepare",
-					"duration":   "0",
-					"offset":     "999",
+					"duration":   "1000",
+					"mode":       "DRIFT",
+					"offset":     "0",
+					"driftRate":  "-2000",
 					"disableNtp": "true",
 				},
 				ExecutionId: uuid.New(),
@@ -59,7 +154,29 @@ func TestActionTimeTravel_Prepare(t *testing.T) {
 				}),
 			},
 
-			wantedError: "Duration must be greater / equal than 1s",
+			wantedState: &TimeTravelActionState{
+				Mode:       timeTravelModeDrift,
+				DriftPpm:   -2000,
+				DisableNtp: true,
+			},
+		}, {
+			name: "Should return error for zero drift rate",
+			requestBody: action_kit_api.PrepareActionRequestBody{
+				Config: map[string]interface{}{
+					"action":    "prepare",
+					"duration":  "1000",
+					"mode":      "DRIFT",
+					"driftRate": "0",
+				},
+				ExecutionId: uuid.New(),
+				Target: extutil.Ptr(action_kit_api.Target{
+					Attributes: map[string][]string{
+						"host.hostname": {"myhostname"},
+					},
+				}),
+			},
+
+			wantedError: "Drift rate must not be 0",
 		},
 	}
 	action := NewTimetravelAction(nil)
@@ -86,6 +203,10 @@ func TestActionTimeTravel_Prepare(t *testing.T) {
 				assert.Equal(t, tt.wantedState.OffsetApplied, state.OffsetApplied)
 				assert.Equal(t, tt.wantedState.Offset, state.Offset)
 				assert.Equal(t, tt.wantedState.DisableNtp, state.DisableNtp)
+				if tt.wantedState.Mode != "" {
+					assert.Equal(t, tt.wantedState.Mode, state.Mode)
+				}
+				assert.Equal(t, tt.wantedState.DriftPpm, state.DriftPpm)
 			}
 		})
 	}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/steadybit/extension-host/exthost/hostns"
+)
+
+var (
+	cgroupBasePath = "/sys/fs/cgroup"
+	sysBasePath    = "/sys"
+)
+
+var readProcCgroup = func(ctx context.Context, pid int) (string, error) {
+	// read the file within the host's cgroup namespace to get the paths relative to the cgroup root
+	return hostns.Run(ctx, "cat", filepath.Join("/proc", strconv.Itoa(pid), "cgroup"))
+}
+
+// Cgroup references a cgroup of the host. For cgroup v1 the controller determines the hierarchy.
+type Cgroup struct {
+	Path       string
+	Controller string
+	V2         bool
+}
+
+func (c Cgroup) String() string {
+	if c.V2 {
+		return c.Path
+	}
+	return fmt.Sprintf("%s:%s", c.Controller, c.Path)
+}
+
+// IsRoot reports whether the cgroup is the root of the hierarchy, which can't be limited.
+func (c Cgroup) IsRoot() bool {
+	return c.Path == "/" || c.Path == ""
+}
+
+func (c Cgroup) dir() string {
+	if c.V2 {
+		return filepath.Join(cgroupBasePath, c.Path)
+	}
+	return filepath.Join(cgroupBasePath, c.Controller, c.Path)
+}
+
+func (c Cgroup) ReadFile(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir(), name))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s of cgroup %s: %w", name, c, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (c Cgroup) WriteFile(name, value string) error {
+	if err := os.WriteFile(filepath.Join(c.dir(), name), []byte(value), 0644); err != nil {
+		return fmt.Errorf("failed to write '%s' to %s of cgroup %s: %w", value, name, c, err)
+	}
+	return nil
+}
+
+// IsV2 reports whether the host uses the unified cgroup v2 hierarchy.
+func IsV2() bool {
+	_, err := os.Stat(filepath.Join(cgroupBasePath, "cgroup.controllers"))
+	return err == nil
+}
+
+// FromPath returns the cgroup for a path relative to the root of the hierarchy.
+func FromPath(path, controller string) Cgroup {
+	return Cgroup{Path: path, Controller: controller, V2: IsV2()}
+}
+
+// ProcessCgroup returns the cgroup of the process for the given controller.
+func ProcessCgroup(ctx context.Context, pid int, controller string) (Cgroup, error) {
+	out, err := readProcCgroup(ctx, pid)
+	if err != nil {
+		return Cgroup{}, fmt.Errorf("failed to read cgroup of process %d: %w", pid, err)
+	}
+
+	paths := parseProcCgroup(out)
+	v2 := IsV2()
+	key := controller
+	if v2 {
+		key = ""
+	}
+	path, ok := paths[key]
+	if !ok {
+		return Cgroup{}, fmt.Errorf("process %d has no cgroup for controller %s", pid, controller)
+	}
+	return Cgroup{Path: path, Controller: controller, V2: v2}, nil
+}
+
+// parseProcCgroup parses /proc/<pid>/cgroup and returns the paths by controller. The unified hierarchy uses the empty key.
+func parseProcCgroup(s string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[1] == "" {
+			result[""] = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			result[strings.TrimPrefix(controller, "name=")] = fields[2]
+		}
+	}
+	return result
+}
+
+// IoDevice returns the major:minor of the block device to be used for io limits.
+// Limits can't be applied to partitions, so the whole disk is returned for these.
+func IoDevice(major, minor uint32) (string, error) {
+	dev := fmt.Sprintf("%d:%d", major, minor)
+	devDir := filepath.Join(sysBasePath, "dev", "block", dev)
+	if _, err := os.Stat(filepath.Join(devDir, "partition")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return dev, nil
+		}
+		return "", fmt.Errorf("failed to check block device %s: %w", dev, err)
+	}
+
+	partitionDir, err := filepath.EvalSymlinks(devDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve partition %s: %w", dev, err)
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(partitionDir), "dev"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read disk of partition %s: %w", dev, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeCgroupRoot(t *testing.T, v2 bool) {
+	oldBasePath := cgroupBasePath
+	cgroupBasePath = t.TempDir()
+	t.Cleanup(func() {
+		cgroupBasePath = oldBasePath
+	})
+	if v2 {
+		fakeCgroupFile(t, "cgroup.controllers", "cpu io memory")
+	}
+}
+
+func fakeCgroupFile(t *testing.T, name, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(cgroupBasePath, filepath.Dir(name)), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join(cgroupBasePath, name), []byte(content), 0666))
+}
+
+func fakeProcCgroup(t *testing.T, content string) {
+	oldReadProcCgroup := readProcCgroup
+	readProcCgroup = func(ctx context.Context, pid int) (string, error) {
+		return content, nil
+	}
+	t.Cleanup(func() {
+		readProcCgroup = oldReadProcCgroup
+	})
+}
+
+func Test_parseProcCgroup(t *testing.T) {
+	assert.Equal(t, map[string]string{"": "/system.slice/nginx.service"}, parseProcCgroup("0::/system.slice/nginx.service\n"))
+	assert.Equal(t, map[string]string{
+		"cpu":     "/system.slice/nginx.service",
+		"cpuacct": "/system.slice/nginx.service",
+		"memory":  "/system.slice",
+		"systemd": "/system.slice/nginx.service",
+		"":        "/system.slice/nginx.service",
+	}, parseProcCgroup(`12:memory:/system.slice
+4:cpu,cpuacct:/system.slice/nginx.service
+1:name=systemd:/system.slice/nginx.service
+0::/system.slice/nginx.service`))
+}
+
+func TestProcessCgroup(t *testing.T) {
+	t.Run("v2", func(t *testing.T) {
+		fakeCgroupRoot(t, true)
+		fakeProcCgroup(t, "0::/system.slice/nginx.service\n")
+
+		c, err := ProcessCgroup(context.Background(), 42, "io")
+		require.NoError(t, err)
+		assert.Equal(t, Cgroup{Path: "/system.slice/nginx.service", Controller: "io", V2: true}, c)
+		assert.Equal(t, filepath.Join(cgroupBasePath, "system.slice", "nginx.service"), c.dir())
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		fakeCgroupRoot(t, false)
+		fakeProcCgroup(t, "4:cpu,cpuacct:/system.slice/nginx.service\n2:memory:/system.slice\n")
+
+		c, err := ProcessCgroup(context.Background(), 42, "cpu")
+		require.NoError(t, err)
+		assert.Equal(t, Cgroup{Path: "/system.slice/nginx.service", Controller: "cpu", V2: false}, c)
+		assert.Equal(t, filepath.Join(cgroupBasePath, "cpu", "system.slice", "nginx.service"), c.dir())
+
+		_, err = ProcessCgroup(context.Background(), 42, "blkio")
+		assert.Error(t, err)
+	})
+}
+
+func TestIoMax(t *testing.T) {
+	fakeCgroupRoot(t, true)
+	fakeCgroupFile(t, "system.slice/nginx.service/io.max", "8:0 rbps=1048576 wbps=max riops=max wiops=max\n259:0 rbps=max wbps=max riops=100 wiops=max\n")
+	c := FromPath("/system.slice/nginx.service", "io")
+
+	line, err := ReadIoMax(c, "259:0")
+	require.NoError(t, err)
+	assert.Equal(t, "259:0 rbps=max wbps=max riops=100 wiops=max", line)
+
+	line, err = ReadIoMax(c, "8:16")
+	require.NoError(t, err)
+	assert.Empty(t, line)
+
+	require.NoError(t, WriteIoMax(c, "8:16", IoLimits{ReadBps: 1024, WriteIops: 10}))
+	content, _ := c.ReadFile("io.max")
+	assert.Equal(t, "8:16 rbps=1024 wbps=max riops=max wiops=10", content)
+
+	require.NoError(t, RestoreIoMax(c, "8:16", ""))
+	content, _ = c.ReadFile("io.max")
+	assert.Equal(t, "8:16 rbps=max wbps=max riops=max wiops=max", content)
+
+	assert.Error(t, WriteIoMax(Cgroup{Path: "/", Controller: "blkio"}, "8:16", IoLimits{}))
+}
+
+func TestIoDevice(t *testing.T) {
+	oldBasePath := sysBasePath
+	sysBasePath = t.TempDir()
+	t.Cleanup(func() {
+		sysBasePath = oldBasePath
+	})
+	disk := filepath.Join(sysBasePath, "devices", "nvme0n1")
+	require.NoError(t, os.MkdirAll(filepath.Join(disk, "nvme0n1p2"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(disk, "dev"), []byte("259:0\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(disk, "nvme0n1p2", "partition"), []byte("2\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(sysBasePath, "dev", "block"), 0755))
+	require.NoError(t, os.Symlink(disk, filepath.Join(sysBasePath, "dev", "block", "259:0")))
+	require.NoError(t, os.Symlink(filepath.Join(disk, "nvme0n1p2"), filepath.Join(sysBasePath, "dev", "block", "259:2")))
+
+	dev, err := IoDevice(259, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "259:0", dev)
+
+	dev, err = IoDevice(259, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "259:0", dev)
+}
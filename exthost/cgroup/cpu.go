@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	cpuMaxFile       = "cpu.max"
+	cpuWeightFile    = "cpu.weight"
+	cpuCfsQuotaFile  = "cpu.cfs_quota_us"
+	cpuCfsPeriodFile = "cpu.cfs_period_us"
+	cpuSharesFile    = "cpu.shares"
+
+	defaultCpuWeight = 100
+	defaultCpuShares = 1024
+)
+
+// CpuSettings holds the raw content of the cpu controller files, so they can be restored exactly.
+type CpuSettings struct {
+	Max       string
+	Weight    string
+	CfsQuota  string
+	CfsPeriod string
+	Shares    string
+}
+
+func ReadCpuSettings(c Cgroup) (CpuSettings, error) {
+	var s CpuSettings
+	var err error
+	if c.V2 {
+		if s.Max, err = c.ReadFile(cpuMaxFile); err != nil {
+			return s, err
+		}
+		if s.Weight, err = c.ReadFile(cpuWeightFile); err != nil {
+			return s, err
+		}
+		return s, nil
+	}
+
+	if s.CfsQuota, err = c.ReadFile(cpuCfsQuotaFile); err != nil {
+		return s, err
+	}
+	if s.CfsPeriod, err = c.ReadFile(cpuCfsPeriodFile); err != nil {
+		return s, err
+	}
+	if s.Shares, err = c.ReadFile(cpuSharesFile); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+func RestoreCpuSettings(c Cgroup, s CpuSettings) error {
+	if c.V2 {
+		return errors.Join(
+			c.WriteFile(cpuMaxFile, s.Max),
+			c.WriteFile(cpuWeightFile, s.Weight),
+		)
+	}
+
+	// lift the quota first, otherwise the period may be rejected
+	if err := c.WriteFile(cpuCfsQuotaFile, "-1"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return errors.Join(
+		c.WriteFile(cpuCfsPeriodFile, s.CfsPeriod),
+		c.WriteFile(cpuCfsQuotaFile, s.CfsQuota),
+		c.WriteFile(cpuSharesFile, s.Shares),
+	)
+}
+
+// LimitCpuQuota limits the cgroup to the given cpu time per period.
+func LimitCpuQuota(c Cgroup, quota, period time.Duration) error {
+	if quota < time.Millisecond || period < time.Millisecond || period > time.Second {
+		return fmt.Errorf("invalid cpu quota %s per period %s", quota, period)
+	}
+
+	if c.V2 {
+		return c.WriteFile(cpuMaxFile, fmt.Sprintf("%d %d", quota.Microseconds(), period.Microseconds()))
+	}
+	if err := c.WriteFile(cpuCfsPeriodFile, fmt.Sprintf("%d", period.Microseconds())); err != nil {
+		return err
+	}
+	return c.WriteFile(cpuCfsQuotaFile, fmt.Sprintf("%d", quota.Microseconds()))
+}
+
+// SetCpuWeight sets the relative cpu weight (1-10000, default 100). For cgroup v1 the weight is converted to cpu.shares.
+func SetCpuWeight(c Cgroup, weight uint64) error {
+	if weight < 1 || weight > 10000 {
+		return fmt.Errorf("invalid cpu weight %d, must be between 1 and 10000", weight)
+	}
+
+	if c.V2 {
+		return c.WriteFile(cpuWeightFile, fmt.Sprintf("%d", weight))
+	}
+	shares := max(weight*defaultCpuShares/defaultCpuWeight, 2)
+	return c.WriteFile(cpuSharesFile, fmt.Sprintf("%d", shares))
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCpuSettings_v2(t *testing.T) {
+	fakeCgroupRoot(t, true)
+	fakeCgroupFile(t, "system.slice/nginx.service/cpu.max", "max 100000\n")
+	fakeCgroupFile(t, "system.slice/nginx.service/cpu.weight", "100\n")
+	c := FromPath("/system.slice/nginx.service", "cpu")
+
+	original, err := ReadCpuSettings(c)
+	require.NoError(t, err)
+	assert.Equal(t, CpuSettings{Max: "max 100000", Weight: "100"}, original)
+
+	require.NoError(t, LimitCpuQuota(c, 50*time.Millisecond, 100*time.Millisecond))
+	require.NoError(t, SetCpuWeight(c, 10))
+	limited, _ := ReadCpuSettings(c)
+	assert.Equal(t, CpuSettings{Max: "50000 100000", Weight: "10"}, limited)
+
+	require.NoError(t, RestoreCpuSettings(c, original))
+	restored, _ := ReadCpuSettings(c)
+	assert.Equal(t, original, restored)
+}
+
+func TestCpuSettings_v1(t *testing.T) {
+	fakeCgroupRoot(t, false)
+	fakeCgroupFile(t, "cpu/system.slice/nginx.service/cpu.cfs_quota_us", "-1\n")
+	fakeCgroupFile(t, "cpu/system.slice/nginx.service/cpu.cfs_period_us", "100000\n")
+	fakeCgroupFile(t, "cpu/system.slice/nginx.service/cpu.shares", "1024\n")
+	c := FromPath("/system.slice/nginx.service", "cpu")
+
+	original, err := ReadCpuSettings(c)
+	require.NoError(t, err)
+	assert.Equal(t, CpuSettings{CfsQuota: "-1", CfsPeriod: "100000", Shares: "1024"}, original)
+
+	require.NoError(t, LimitCpuQuota(c, 25*time.Millisecond, 50*time.Millisecond))
+	require.NoError(t, SetCpuWeight(c, 50))
+	limited, _ := ReadCpuSettings(c)
+	assert.Equal(t, CpuSettings{CfsQuota: "25000", CfsPeriod: "50000", Shares: "512"}, limited)
+
+	require.NoError(t, RestoreCpuSettings(c, original))
+	restored, _ := ReadCpuSettings(c)
+	assert.Equal(t, original, restored)
+}
+
+func TestLimitCpuQuota_invalid(t *testing.T) {
+	c := Cgroup{Path: "/system.slice", V2: true}
+	assert.Error(t, LimitCpuQuota(c, 0, 100*time.Millisecond))
+	assert.Error(t, LimitCpuQuota(c, 10*time.Millisecond, 2*time.Second))
+	assert.Error(t, SetCpuWeight(c, 0))
+	assert.Error(t, SetCpuWeight(c, 10001))
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var freezeTimeout = 5 * time.Second
+
+// IsFrozen reports whether the cgroup is frozen via cgroup.freeze. Only supported for cgroup v2.
+func IsFrozen(c Cgroup) (bool, error) {
+	if !c.V2 {
+		return false, errors.New("freezing requires cgroup v2")
+	}
+	value, err := c.ReadFile("cgroup.freeze")
+	if err != nil {
+		return false, err
+	}
+	return value == "1", nil
+}
+
+// Freeze freezes all processes of the cgroup and its descendants and waits until they are frozen.
+func Freeze(c Cgroup) error {
+	if !c.V2 {
+		return errors.New("freezing requires cgroup v2")
+	}
+	if err := c.WriteFile("cgroup.freeze", "1"); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(freezeTimeout)
+	for {
+		events, err := c.ReadFile("cgroup.events")
+		if err != nil {
+			return err
+		}
+		if parseEvents(events)["frozen"] == "1" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cgroup %s not frozen within %s", c, freezeTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Thaw resumes the processes of a frozen cgroup.
+func Thaw(c Cgroup) error {
+	if !c.V2 {
+		return errors.New("freezing requires cgroup v2")
+	}
+	return c.WriteFile("cgroup.freeze", "0")
+}
+
+func parseEvents(s string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		if key, value, found := strings.Cut(strings.TrimSpace(line), " "); found {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeze(t *testing.T) {
+	fakeCgroupRoot(t, true)
+	fakeCgroupFile(t, "system.slice/nginx.service/cgroup.freeze", "0\n")
+	fakeCgroupFile(t, "system.slice/nginx.service/cgroup.events", "populated 1\nfrozen 1\n")
+	c := FromPath("/system.slice/nginx.service", "")
+
+	frozen, err := IsFrozen(c)
+	require.NoError(t, err)
+	assert.False(t, frozen)
+
+	require.NoError(t, Freeze(c))
+	frozen, _ = IsFrozen(c)
+	assert.True(t, frozen)
+
+	require.NoError(t, Thaw(c))
+	frozen, _ = IsFrozen(c)
+	assert.False(t, frozen)
+}
+
+func TestFreeze_timeout(t *testing.T) {
+	oldFreezeTimeout := freezeTimeout
+	freezeTimeout = 100 * time.Millisecond
+	t.Cleanup(func() {
+		freezeTimeout = oldFreezeTimeout
+	})
+	fakeCgroupRoot(t, true)
+	fakeCgroupFile(t, "system.slice/nginx.service/cgroup.freeze", "0\n")
+	fakeCgroupFile(t, "system.slice/nginx.service/cgroup.events", "populated 1\nfrozen 0\n")
+	c := FromPath("/system.slice/nginx.service", "")
+
+	assert.ErrorContains(t, Freeze(c), "not frozen within")
+	content, err := os.ReadFile(filepath.Join(cgroupBasePath, "system.slice/nginx.service/cgroup.freeze"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(content))
+}
+
+func TestFreeze_v1(t *testing.T) {
+	fakeCgroupRoot(t, false)
+	c := FromPath("/system.slice/nginx.service", "freezer")
+
+	assert.ErrorContains(t, Freeze(c), "requires cgroup v2")
+	assert.ErrorContains(t, Thaw(c), "requires cgroup v2")
+	_, err := IsFrozen(c)
+	assert.ErrorContains(t, err, "requires cgroup v2")
+}
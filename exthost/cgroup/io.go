@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const ioMaxFile = "io.max"
+
+// IoLimits are the limits of io.max. Zero values are unlimited.
+type IoLimits struct {
+	ReadBps   uint64
+	WriteBps  uint64
+	ReadIops  uint64
+	WriteIops uint64
+}
+
+func (l IoLimits) IsZero() bool {
+	return l == IoLimits{}
+}
+
+func (l IoLimits) String() string {
+	return fmt.Sprintf("rbps=%s wbps=%s riops=%s wiops=%s", limitValue(l.ReadBps), limitValue(l.WriteBps), limitValue(l.ReadIops), limitValue(l.WriteIops))
+}
+
+func limitValue(v uint64) string {
+	if v == 0 {
+		return "max"
+	}
+	return fmt.Sprintf("%d", v)
+}
+
+// ReadIoMax returns the io.max line for the device or an empty string if no limits are set.
+func ReadIoMax(c Cgroup, device string) (string, error) {
+	if !c.V2 {
+		return "", errors.New("io.max is only available with cgroup v2")
+	}
+	content, err := c.ReadFile(ioMaxFile)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, device+" ") {
+			return line, nil
+		}
+	}
+	return "", nil
+}
+
+// WriteIoMax sets the limits for the device.
+func WriteIoMax(c Cgroup, device string, limits IoLimits) error {
+	if !c.V2 {
+		return errors.New("io.max is only available with cgroup v2")
+	}
+	return c.WriteFile(ioMaxFile, fmt.Sprintf("%s %s", device, limits))
+}
+
+// RestoreIoMax restores a line previously returned by ReadIoMax. An empty line removes all limits of the device.
+func RestoreIoMax(c Cgroup, device, line string) error {
+	if line == "" {
+		return WriteIoMax(c, device, IoLimits{})
+	}
+	return c.WriteFile(ioMaxFile, line)
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPids(t *testing.T) {
+	fakeCgroupRoot(t, true)
+	fakeCgroupFile(t, "system.slice/nginx.service/cgroup.procs", "100\n101\n")
+	fakeCgroupFile(t, "system.slice/nginx.service/worker/cgroup.procs", "102\n")
+	fakeCgroupFile(t, "system.slice/other.service/cgroup.procs", "200\n")
+
+	pids, err := Pids(FromPath("/system.slice/nginx.service", ""))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{100, 101, 102}, pids)
+
+	_, err = Pids(FromPath("/system.slice/missing.service", ""))
+	assert.Error(t, err)
+}
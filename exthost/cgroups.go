@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-host/exthost/cgroup"
+	stopprocess "github.com/steadybit/extension-host/exthost/process"
+	"github.com/steadybit/extension-host/exthost/systemd"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// resolveActionCgroups returns the cgroup of the targeted service or, for host targets, the cgroups of the process or unit given in the config.
+func resolveActionCgroups(ctx context.Context, service bool, request action_kit_api.PrepareActionRequestBody, controller string) ([]cgroup.Cgroup, error) {
+	if service {
+		_, path, err := targetService(ctx, request.Target.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		return []cgroup.Cgroup{cgroup.FromPath(path, controller)}, nil
+	}
+	return resolveCgroups(ctx, stopprocess.Filter{Process: extutil.ToString(request.Config["process"])}, extutil.ToString(request.Config["unit"]), controller)
+}
+
+// resolveCgroups returns the distinct cgroups of the systemd unit or, if no unit is given, of the processes matching the filter.
+func resolveCgroups(ctx context.Context, filter stopprocess.Filter, unit, controller string) ([]cgroup.Cgroup, error) {
+	if unit != "" {
+		path, err := systemd.ControlGroup(ctx, unit)
+		if err != nil {
+			return nil, err
+		}
+		return []cgroup.Cgroup{cgroup.FromPath(path, controller)}, nil
+	}
+
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("either a process or a systemd unit is required")
+	}
+
+	pids, err := stopprocess.FindProcesses(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no process found matching '%s'", filter)
+	}
+
+	var result []cgroup.Cgroup
+	seen := make(map[string]bool)
+	for _, pid := range pids {
+		c, err := cgroup.ProcessCgroup(ctx, pid, controller)
+		if err != nil {
+			log.Debug().Err(err).Int("pid", pid).Msg("Failed to read cgroup of process, the process might have exited")
+			continue
+		}
+		if seen[c.String()] {
+			continue
+		}
+		if c.IsRoot() {
+			return nil, fmt.Errorf("process %d is in the root cgroup, which can't be limited", pid)
+		}
+		seen[c.String()] = true
+		result = append(result, c)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("failed to read the cgroups of the processes matching '%s'", filter)
+	}
+	return result, nil
+}
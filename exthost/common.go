@@ -4,11 +4,16 @@
 package exthost
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/extension-host/config"
+	"github.com/steadybit/extension-host/exthost/systemd"
 	"github.com/steadybit/extension-kit"
 	"github.com/steadybit/extension-kit/extutil"
 )
@@ -18,6 +23,8 @@ const (
 	targetIcon   = "data:image/svg+xml,%3Csvg%20width%3D%2224%22%20height%3D%2224%22%20viewBox%3D%220%200%2024%2024%22%20fill%3D%22none%22%20xmlns%3D%22http%3A%2F%2Fwww.w3.org%2F2000%2Fsvg%22%3E%3Cpath%20fill-rule%3D%22evenodd%22%20clip-rule%3D%22evenodd%22%20d%3D%22M11.65%202.064a.993.993%200%2001.7%200l10%203.776a1.01%201.01%200%20010%201.889l-10%203.773a.993.993%200%2001-.7%200l-10-3.773A1.008%201.008%200%20011%206.784c0-.42.259-.796.65-.944l10-3.776zM1.063%2017.03a.998.998%200%20011.287-.591L12%2020.082l9.649-3.644a.998.998%200%20011.287.59%201.01%201.01%200%2001-.586%201.299l-10%203.776a.993.993%200%2001-.7%200l-10-3.776a1.01%201.01%200%2001-.586-1.298zm1.287-5.89a.998.998%200%2000-1.287.59%201.01%201.01%200%2000.586%201.299l10%203.776a.993.993%200%2000.7%200l10-3.776a1.01%201.01%200%2000.586-1.298.998.998%200%2000-1.287-.59L12%2014.782l-9.649-3.644z%22%20fill%3D%22currentColor%22%2F%3E%3C%2Fsvg%3E"
 	BaseActionID = "com.steadybit.extension_host"
 
+	serviceTargetID = "com.steadybit.extension_host.service"
+
 	shutdownActionID = BaseActionID + ".shutdown"
 	shutdownIcon     = "data:image/svg+xml,%3Csvg%20width%3D%2219%22%20height%3D%2222%22%20viewBox%3D%220%200%2019%2022%22%20fill%3D%22none%22%20xmlns%3D%22http%3A%2F%2Fwww.w3.org%2F2000%2Fsvg%22%3E%0A%3Cpath%20fill-rule%3D%22evenodd%22%20clip-rule%3D%22evenodd%22%20d%3D%22M9.23122%200C9.64544%200%209.98122%200.335786%209.98122%200.75V10.0536C9.98122%2010.4678%209.64544%2010.8036%209.23122%2010.8036C8.81701%2010.8036%208.48122%2010.4678%208.48122%2010.0536V0.75C8.48122%200.335786%208.81701%200%209.23122%200ZM11.3867%203.85221C11.5248%203.46167%2011.9533%203.25699%2012.3438%203.39503C14.1646%204.03861%2015.741%205.23087%2016.856%206.8076C17.971%208.38434%2018.5697%2010.268%2018.5697%2012.1991C18.5697%2014.1303%2017.971%2016.0139%2016.856%2017.5907C15.741%2019.1674%2014.1646%2020.3597%2012.3438%2021.0032L12.3345%2021.0065C10.0089%2021.7942%207.46664%2021.6342%205.2581%2020.5613C3.04956%2019.4884%201.35239%2017.5889%200.533964%2015.274C-0.284465%2012.9591%20-0.158301%2010.415%200.885145%208.19237C1.92859%205.96978%203.80537%204.24753%206.10922%203.39843C6.49787%203.25518%206.92906%203.45413%207.07231%203.84279C7.21555%204.23145%207.0166%204.66264%206.62794%204.80588C4.69413%205.5186%203.11881%206.96422%202.24296%208.82983C1.36711%2010.6954%201.26121%2012.8309%201.94818%2014.774C2.63515%2016.7171%204.05973%2018.3115%205.91353%2019.2121C7.76584%2020.1119%209.89777%2020.2466%2011.8485%2019.5874C13.3749%2019.0468%2014.6963%2018.0467%2015.6313%2016.7246C16.5672%2015.4011%2017.0697%2013.8201%2017.0697%2012.1991C17.0697%2010.5782%2016.5672%208.99713%2015.6313%207.67367C14.6954%206.35022%2013.3722%205.34948%2011.8439%204.80928C11.4534%204.67124%2011.2487%204.24274%2011.3867%203.85221Z%22%20fill%3D%22%231D2632%22%2F%3E%0A%3C%2Fsvg%3E%0A"
 
@@ -50,6 +57,14 @@ var (
 		},
 	}
 
+	serviceTargetSelectionTemplates = []action_kit_api.TargetSelectionTemplate{
+		{
+			Label:       "service name",
+			Description: extutil.Ptr("Find service by host name and service name."),
+			Query:       "host.hostname=\"\" AND host.service.name=\"\"",
+		},
+	}
+
 	osHostname = func() (string, error) {
 		hostname := config.Config.Hostname
 		if hostname == "" {
@@ -75,6 +90,39 @@ func CheckTargetHostname(attributes map[string][]string) (*string, error) {
 	return extutil.Ptr(osHostname), nil
 }
 
+// serviceActionDescription turns the description of a host action into its variant targeting a systemd service, the given parameters are dropped
+func serviceActionDescription(d action_kit_api.ActionDescription, dropParameters ...string) action_kit_api.ActionDescription {
+	d.Id = strings.Replace(d.Id, BaseActionID, BaseActionID+".service", 1)
+	d.TargetSelection = &action_kit_api.TargetSelection{
+		TargetType:         serviceTargetID,
+		SelectionTemplates: &serviceTargetSelectionTemplates,
+	}
+	d.Parameters = slices.DeleteFunc(slices.Clone(d.Parameters), func(p action_kit_api.ActionParameter) bool {
+		return slices.Contains(dropParameters, p.Name)
+	})
+	return d
+}
+
+var serviceControlGroup = systemd.ControlGroup
+
+// targetService returns the unit and the cgroup path of a service target, the cgroup is read from systemd if the attribute was excluded
+func targetService(ctx context.Context, attributes map[string][]string) (unit, cgroupPath string, err error) {
+	if names := attributes["host.service.name"]; len(names) > 0 {
+		unit = names[0]
+	}
+	if unit == "" {
+		return "", "", errors.New("target is missing the 'host.service.name' attribute")
+	}
+	if paths := attributes["host.service.cgroup"]; len(paths) > 0 && paths[0] != "" {
+		return unit, paths[0], nil
+	}
+	cgroupPath, err = serviceControlGroup(ctx, unit)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the cgroup of unit %s: %w", unit, err)
+	}
+	return unit, cgroupPath, nil
+}
+
 func getRestrictedEndpoints(request action_kit_api.PrepareActionRequestBody) []action_kit_api.RestrictedEndpoint {
 	var restrictedEndpoints []action_kit_api.RestrictedEndpoint
 	if request.ExecutionContext != nil && request.ExecutionContext.RestrictedEndpoints != nil {
@@ -1,9 +1,13 @@
 package exthost
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/extension-kit/extutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -57,3 +61,106 @@ func TestCheckTargetHostname(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceActionDescription(t *testing.T) {
+	tests := []struct {
+		name              string
+		description       action_kit_api.ActionDescription
+		wantedId          string
+		droppedParameters []string
+		keptParameters    []string
+	}{
+		{
+			name:              "pause",
+			description:       NewPauseServiceAction().Describe(),
+			wantedId:          "com.steadybit.extension_host.service.pause-process",
+			droppedParameters: []string{"process", "unit"},
+			keptParameters:    []string{"duration", "mode", "cmdline", "user"},
+		},
+		{
+			name:              "stop processes",
+			description:       NewStopServiceProcessesAction().Describe(),
+			wantedId:          "com.steadybit.extension_host.service.stop-process",
+			droppedParameters: []string{"process"},
+			keptParameters:    []string{"duration", "signal", "cmdline", "user"},
+		},
+		{
+			name:              "io throttle",
+			description:       NewIoThrottleServiceAction().Describe(),
+			wantedId:          "com.steadybit.extension_host.service.io-throttle",
+			droppedParameters: []string{"process", "unit"},
+			keptParameters:    []string{"duration", "path"},
+		},
+		{
+			name:              "limit cpu",
+			description:       NewLimitCpuServiceAction().Describe(),
+			wantedId:          "com.steadybit.extension_host.service.limit-cpu",
+			droppedParameters: []string{"process", "unit"},
+			keptParameters:    []string{"duration", "mode"},
+		},
+		{
+			name:              "time namespace",
+			description:       NewTimeNamespaceServiceAction().Describe(),
+			wantedId:          "com.steadybit.extension_host.service.time-namespace",
+			droppedParameters: []string{"mode", "command", "unit"},
+			keptParameters:    []string{"duration", "offset", "direction"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantedId, tt.description.Id)
+			assert.Equal(t, serviceTargetID, tt.description.TargetSelection.TargetType)
+			var names []string
+			for _, p := range tt.description.Parameters {
+				names = append(names, p.Name)
+			}
+			for _, name := range tt.droppedParameters {
+				assert.NotContains(t, names, name)
+			}
+			for _, name := range tt.keptParameters {
+				assert.Contains(t, names, name)
+			}
+		})
+	}
+
+	// the host variant keeps its parameters
+	assert.Equal(t, targetID, NewPauseProcessAction().Describe().TargetSelection.TargetType)
+	assert.Len(t, NewPauseProcessAction().Describe().Parameters, 6)
+}
+
+func TestTargetService(t *testing.T) {
+	oldServiceControlGroup := serviceControlGroup
+	t.Cleanup(func() {
+		serviceControlGroup = oldServiceControlGroup
+	})
+	serviceControlGroup = func(_ context.Context, unit string) (string, error) {
+		if unit == "nginx.service" {
+			return "/system.slice/nginx.service", nil
+		}
+		return "", errors.New("unit not found")
+	}
+
+	unit, path, err := targetService(context.Background(), map[string][]string{
+		"host.service.name":   {"sshd.service"},
+		"host.service.cgroup": {"/system.slice/sshd.service"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sshd.service", unit)
+	assert.Equal(t, "/system.slice/sshd.service", path)
+
+	// the cgroup attribute may be excluded from discovery
+	unit, path, err = targetService(context.Background(), map[string][]string{
+		"host.service.name": {"nginx.service"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx.service", unit)
+	assert.Equal(t, "/system.slice/nginx.service", path)
+
+	_, _, err = targetService(context.Background(), map[string][]string{
+		"host.service.name": {"missing.service"},
+	})
+	assert.ErrorContains(t, err, "unit not found")
+
+	_, _, err = targetService(context.Background(), map[string][]string{})
+	assert.ErrorContains(t, err, "host.service.name")
+}
@@ -5,9 +5,11 @@
 package cpufreq
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -19,101 +21,320 @@ const (
 	curFreqFile    = "scaling_cur_freq"
 	scalingMinFile = "scaling_min_freq"
 	scalingMaxFile = "scaling_max_freq"
+	governorFile   = "scaling_governor"
+	governorsFile  = "scaling_available_governors"
 	khzToMhz       = 1000 // Convert kHz to MHz
 )
 
 var cpuBasePath = "/sys/devices/system/cpu"
 
-// GetCPUFrequencyInfo returns the minimum and maximum CPU frequencies in MHz
+// CPUFrequencyInfo holds frequency limits of a single CPU in MHz
+type CPUFrequencyInfo struct {
+	Cpu     int
+	MinFreq uint64
+	MaxFreq uint64
+}
+
+// GetCPUFrequencyInfo returns the minimum and maximum CPU frequencies in MHz over all CPUs
 func GetCPUFrequencyInfo() (min, max uint64, err error) {
-	cpus, err := listCpuDirs()
+	infos, err := GetCPUFrequencyInfoPerCpu(nil)
 	if err != nil {
 		return 0, 0, err
 	}
+	min, max = FrequencyRange(infos)
+	return min, max, nil
+}
 
-	cpuPath := filepath.Join(cpus[0], "cpufreq")
-
-	minFreq, err := readFrequencyFile(filepath.Join(cpuPath, minFreqFile))
+// GetCPUFrequencyInfoPerCpu returns the hardware frequency limits in MHz of the given CPUs, or of all CPUs if none are given
+func GetCPUFrequencyInfoPerCpu(cpus []int) ([]CPUFrequencyInfo, error) {
+	cpus, err := resolveCpus(cpus)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to read min frequency: %w", err)
+		return nil, err
 	}
 
-	maxFreq, err := readFrequencyFile(filepath.Join(cpuPath, maxFreqFile))
+	infos := make([]CPUFrequencyInfo, 0, len(cpus))
+	for _, cpu := range cpus {
+		cpuPath := filepath.Join(cpuDir(cpu), "cpufreq")
+
+		minFreq, err := readFrequencyFile(filepath.Join(cpuPath, minFreqFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read min frequency of cpu%d: %w", cpu, err)
+		}
+
+		maxFreq, err := readFrequencyFile(filepath.Join(cpuPath, maxFreqFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read max frequency of cpu%d: %w", cpu, err)
+		}
+
+		// Convert kHz to MHz
+		infos = append(infos, CPUFrequencyInfo{Cpu: cpu, MinFreq: minFreq / khzToMhz, MaxFreq: maxFreq / khzToMhz})
+	}
+	return infos, nil
+}
+
+// GetCurrentFrequencies returns the current frequency in MHz of the given CPUs, or of all CPUs if none are given
+func GetCurrentFrequencies(cpus []int) (map[int]uint64, error) {
+	cpus, err := resolveCpus(cpus)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to read max frequency: %w", err)
+		return nil, err
 	}
 
-	// Convert kHz to MHz
-	return minFreq / khzToMhz, maxFreq / khzToMhz, nil
+	result := make(map[int]uint64, len(cpus))
+	for _, cpu := range cpus {
+		freqKhz, err := readFrequencyFile(filepath.Join(cpuDir(cpu), "cpufreq", curFreqFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read current frequency of cpu%d: %w", cpu, err)
+		}
+		result[cpu] = freqKhz / khzToMhz
+	}
+	return result, nil
 }
 
-// GetCurrentFrequency returns the current CPU frequency in MHz
-func GetCurrentFrequency() (uint64, error) {
-	cpus, err := listCpuDirs()
-	if err != nil {
-		return 0, err
+// SetCPUFrequencyLimitsForCpus sets the minimum and maximum frequency for the given CPUs, or for all CPUs if none are given.
+// The limits must be within the hardware range of the CPUs and are clamped to the hardware limits of each CPU, as
+// cores of hybrid and heterogeneous CPUs support different ranges. The applied limits are returned per CPU.
+func SetCPUFrequencyLimitsForCpus(cpus []int, min, max uint64) ([]CPUFrequencyInfo, error) {
+	if min > max {
+		return nil, fmt.Errorf("minimum frequency %d MHz cannot be greater than maximum frequency %d MHz", min, max)
 	}
 
-	freqKhz, err := readFrequencyFile(filepath.Join(cpus[0], "cpufreq", curFreqFile))
+	// Get hardware min/max to validate requested values
+	infos, err := GetCPUFrequencyInfoPerCpu(cpus)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("failed to get current CPU frequency limits: %w", err)
+	}
+
+	hwMin, hwMax := FrequencyRange(infos)
+	if min < hwMin {
+		return nil, fmt.Errorf("requested minimum frequency %d MHz is below hardware minimum %d MHz", min, hwMin)
+	}
+	if max > hwMax {
+		return nil, fmt.Errorf("requested maximum frequency %d MHz is above hardware maximum %d MHz", max, hwMax)
+	}
+
+	applied := make([]CPUFrequencyInfo, 0, len(infos))
+	for _, info := range infos {
+		limits := CPUFrequencyInfo{
+			Cpu:     info.Cpu,
+			MinFreq: clamp(min, info.MinFreq, info.MaxFreq),
+			MaxFreq: clamp(max, info.MinFreq, info.MaxFreq),
+		}
+		minKhz := strconv.FormatUint(limits.MinFreq*khzToMhz, 10)
+		maxKhz := strconv.FormatUint(limits.MaxFreq*khzToMhz, 10)
+		if err := writeScalingLimits(limits.Cpu, minKhz, maxKhz); err != nil {
+			return applied, err
+		}
+		applied = append(applied, limits)
 	}
 
-	// Convert kHz to MHz
-	return freqKhz / khzToMhz, nil
+	return applied, nil
 }
 
-// SetCPUFrequencyLimits sets the minimum and maximum CPU frequency for all cores
-// Frequencies are specified in MHz but written to sysfs in kHz
-func SetCPUFrequencyLimits(min, max uint64) error {
-	if min > max {
-		return fmt.Errorf("minimum frequency %d MHz cannot be greater than maximum frequency %d MHz", min, max)
+// writeScalingLimits writes the raw limits in kHz in an order keeping min <= max at all times
+func writeScalingLimits(cpu int, minKhz, maxKhz string) error {
+	cpuPath := filepath.Join(cpuDir(cpu), "cpufreq")
+
+	// Set max first when lowering, min first when raising above the current min to avoid invalid states
+	curMinKhz, err := readFrequencyFile(filepath.Join(cpuPath, scalingMinFile))
+	newMaxKhz, parseErr := strconv.ParseUint(strings.TrimSpace(maxKhz), 10, 64)
+	if err != nil || parseErr != nil || newMaxKhz >= curMinKhz {
+		if err := os.WriteFile(filepath.Join(cpuPath, scalingMaxFile), []byte(maxKhz), 0644); err != nil {
+			return fmt.Errorf("failed to set max frequency for cpu%d: %w", cpu, err)
+		}
+		if err := os.WriteFile(filepath.Join(cpuPath, scalingMinFile), []byte(minKhz), 0644); err != nil {
+			return fmt.Errorf("failed to set min frequency for cpu%d: %w", cpu, err)
+		}
+	} else {
+		if err := os.WriteFile(filepath.Join(cpuPath, scalingMinFile), []byte(minKhz), 0644); err != nil {
+			return fmt.Errorf("failed to set min frequency for cpu%d: %w", cpu, err)
+		}
+		if err := os.WriteFile(filepath.Join(cpuPath, scalingMaxFile), []byte(maxKhz), 0644); err != nil {
+			return fmt.Errorf("failed to set max frequency for cpu%d: %w", cpu, err)
+		}
 	}
+	return nil
+}
+
+// ScalingSnapshot holds the raw content of the scaling files of a single CPU, to restore them exactly
+type ScalingSnapshot struct {
+	Cpu      int
+	MinFreq  string
+	MaxFreq  string
+	Governor string
+}
 
-	// Get current min/max to validate requested values
-	curMin, curMax, err := GetCPUFrequencyInfo()
+// SnapshotScaling reads the scaling limits and governor of the given CPUs, or of all CPUs if none are given
+func SnapshotScaling(cpus []int) ([]ScalingSnapshot, error) {
+	cpus, err := resolveCpus(cpus)
 	if err != nil {
-		return fmt.Errorf("failed to get current CPU frequency limits: %w", err)
+		return nil, err
 	}
 
-	if min < curMin {
-		return fmt.Errorf("requested minimum frequency %d MHz is below hardware minimum %d MHz", min, curMin)
+	snapshots := make([]ScalingSnapshot, 0, len(cpus))
+	for _, cpu := range cpus {
+		snapshot := ScalingSnapshot{Cpu: cpu}
+		for file, value := range map[string]*string{scalingMinFile: &snapshot.MinFreq, scalingMaxFile: &snapshot.MaxFreq, governorFile: &snapshot.Governor} {
+			data, err := os.ReadFile(filepath.Join(cpuDir(cpu), "cpufreq", file))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s of cpu%d: %w", file, cpu, err)
+			}
+			*value = string(data)
+		}
+		snapshots = append(snapshots, snapshot)
 	}
-	if max > curMax {
-		return fmt.Errorf("requested maximum frequency %d MHz is above hardware maximum %d MHz", max, curMax)
+	return snapshots, nil
+}
+
+// RestoreScaling writes the snapshots back. The governor is restored first, as switching it may change the limits.
+// All CPUs are restored even if some fail.
+func RestoreScaling(snapshots []ScalingSnapshot) error {
+	var errs []error
+	for _, snapshot := range snapshots {
+		if err := os.WriteFile(filepath.Join(cpuDir(snapshot.Cpu), "cpufreq", governorFile), []byte(snapshot.Governor), 0644); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore governor of cpu%d: %w", snapshot.Cpu, err))
+		}
+		if err := writeScalingLimits(snapshot.Cpu, snapshot.MinFreq, snapshot.MaxFreq); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	// Convert MHz to kHz for sysfs
-	minKhz := min * khzToMhz
-	maxKhz := max * khzToMhz
+// CPUGovernor holds the scaling governor to set for a single CPU
+type CPUGovernor struct {
+	Cpu      int
+	Governor string
+}
 
-	cpus, err := listCpuDirs()
+// CheckGovernorAvailable returns an error if the governor is not available for any of the given CPUs
+func CheckGovernorAvailable(cpus []int, governor string) error {
+	cpus, err := resolveCpus(cpus)
 	if err != nil {
 		return err
 	}
 
 	for _, cpu := range cpus {
-		cpuPath := filepath.Join(cpu, "cpufreq")
+		data, err := os.ReadFile(filepath.Join(cpuDir(cpu), "cpufreq", governorsFile))
+		if err != nil {
+			return fmt.Errorf("failed to read available governors of cpu%d: %w", cpu, err)
+		}
+		available := strings.Fields(string(data))
+		if !slices.Contains(available, governor) {
+			return fmt.Errorf("governor %s is not available for cpu%d, available are %s", governor, cpu, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
 
-		// Set max first when lowering, min first when raising to avoid invalid states
-		if maxKhz < curMax*khzToMhz {
-			if err := writeFrequencyFile(filepath.Join(cpuPath, scalingMaxFile), maxKhz); err != nil {
-				return fmt.Errorf("failed to set max frequency for %s: %w", cpu, err)
-			}
-			if err := writeFrequencyFile(filepath.Join(cpuPath, scalingMinFile), minKhz); err != nil {
-				return fmt.Errorf("failed to set min frequency for %s: %w", cpu, err)
-			}
-		} else {
-			if err := writeFrequencyFile(filepath.Join(cpuPath, scalingMinFile), minKhz); err != nil {
-				return fmt.Errorf("failed to set min frequency for %s: %w", cpu, err)
+// SetGovernors sets the scaling governor of each CPU
+func SetGovernors(governors []CPUGovernor) error {
+	for _, g := range governors {
+		if err := os.WriteFile(filepath.Join(cpuDir(g.Cpu), "cpufreq", governorFile), []byte(g.Governor), 0644); err != nil {
+			return fmt.Errorf("failed to set governor %s for cpu%d: %w", g.Governor, g.Cpu, err)
+		}
+	}
+	return nil
+}
+
+// turboControl returns the sysfs file controlling turbo boost. The intel_pstate driver provides no_turbo,
+// where 1 disables turbo, other drivers provide boost, where 1 enables it.
+func turboControl() (path string, inverted bool, err error) {
+	noTurbo := filepath.Join(cpuBasePath, "intel_pstate", "no_turbo")
+	if _, err := os.Stat(noTurbo); err == nil {
+		return noTurbo, true, nil
+	}
+	boost := filepath.Join(cpuBasePath, "cpufreq", "boost")
+	if _, err := os.Stat(boost); err == nil {
+		return boost, false, nil
+	}
+	return "", false, fmt.Errorf("turbo boost control is not supported, neither intel_pstate/no_turbo nor cpufreq/boost exist")
+}
+
+// GetTurboEnabled returns whether turbo boost is enabled
+func GetTurboEnabled() (bool, error) {
+	path, inverted, err := turboControl()
+	if err != nil {
+		return false, err
+	}
+	value, err := readFrequencyFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read turbo boost state: %w", err)
+	}
+	return (value == 1) != inverted, nil
+}
+
+// SetTurboEnabled enables or disables turbo boost for all CPUs
+func SetTurboEnabled(enabled bool) error {
+	path, inverted, err := turboControl()
+	if err != nil {
+		return err
+	}
+	var value uint64
+	if enabled != inverted {
+		value = 1
+	}
+	if err := writeFrequencyFile(path, value); err != nil {
+		return fmt.Errorf("failed to set turbo boost state: %w", err)
+	}
+	return nil
+}
+
+// ParseCpuList parses a CPU list in the kernel's format, e.g. "0-3,8,10-11", into sorted, unique CPU numbers
+func ParseCpuList(list string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("invalid CPU %q in list %q", part, list)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || last < first {
+				return nil, fmt.Errorf("invalid CPU range %q in list %q", part, list)
 			}
-			if err := writeFrequencyFile(filepath.Join(cpuPath, scalingMaxFile), maxKhz); err != nil {
-				return fmt.Errorf("failed to set max frequency for %s: %w", cpu, err)
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	slices.Sort(cpus)
+	return slices.Compact(cpus), nil
+}
+
+// resolveCpus returns all CPUs supporting frequency scaling if none are given, otherwise validates the given CPUs
+func resolveCpus(cpus []int) ([]int, error) {
+	if len(cpus) > 0 {
+		for _, cpu := range cpus {
+			if _, err := os.Stat(filepath.Join(cpuDir(cpu), "cpufreq")); err != nil {
+				return nil, fmt.Errorf("cpu%d does not exist or does not support frequency scaling", cpu)
 			}
 		}
+		return cpus, nil
 	}
 
-	return nil
+	dirs, err := listCpuDirs()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "cpu"))
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, "cpufreq")); err == nil {
+			cpus = append(cpus, cpu)
+		}
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("no CPUs with frequency scaling found")
+	}
+	slices.Sort(cpus)
+	return cpus, nil
 }
 
 func listCpuDirs() ([]string, error) {
@@ -129,6 +350,33 @@ func listCpuDirs() ([]string, error) {
 	return cpus, nil
 }
 
+func cpuDir(cpu int) string {
+	return filepath.Join(cpuBasePath, fmt.Sprintf("cpu%d", cpu))
+}
+
+// FrequencyRange returns the lowest minimum and highest maximum frequency of the CPUs
+func FrequencyRange(infos []CPUFrequencyInfo) (min, max uint64) {
+	for i, info := range infos {
+		if i == 0 || info.MinFreq < min {
+			min = info.MinFreq
+		}
+		if info.MaxFreq > max {
+			max = info.MaxFreq
+		}
+	}
+	return min, max
+}
+
+func clamp(value, min, max uint64) uint64 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
 // readFrequencyFile reads a frequency value in kHz from a sysfs file
 func readFrequencyFile(path string) (uint64, error) {
 	data, err := os.ReadFile(path)
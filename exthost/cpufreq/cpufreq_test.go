@@ -3,6 +3,7 @@
 package cpufreq
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"testing"
@@ -100,7 +101,7 @@ func fakeCpuFile(t *testing.T, name, content string) {
 	}
 }
 
-func TestGetCurrentFrequency(t *testing.T) {
+func TestGetCurrentFrequenciesContent(t *testing.T) {
 	tests := []struct {
 		name    string
 		content string
@@ -131,19 +132,19 @@ func TestGetCurrentFrequency(t *testing.T) {
 			fakeCpuDirectory(t)
 			fakeCpuFile(t, path.Join("cpu0", "cpufreq", "scaling_cur_freq"), tt.content)
 
-			got, err := GetCurrentFrequency()
+			got, err := GetCurrentFrequencies(nil)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetCurrentFrequency() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetCurrentFrequencies() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
-				t.Errorf("GetCurrentFrequency() got = %v, want %v", got, tt.want)
+			if got[0] != tt.want {
+				t.Errorf("GetCurrentFrequencies() got = %v, want %v", got[0], tt.want)
 			}
 		})
 	}
 }
 
-func TestSetCPUFrequencyLimits(t *testing.T) {
+func TestSetCPUFrequencyLimitsForAllCpus(t *testing.T) {
 	tests := []struct {
 		name           string
 		min            uint64
@@ -189,9 +190,9 @@ func TestSetCPUFrequencyLimits(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			if err := SetCPUFrequencyLimits(tt.min, tt.max); len(tt.wantErr) > 0 {
+			if _, err := SetCPUFrequencyLimitsForCpus(nil, tt.min, tt.max); len(tt.wantErr) > 0 {
 				if err.Error() != tt.wantErr {
-					t.Errorf("SetCPUFrequencyLimits() error = %v, wantErr = %v", err, tt.wantErr)
+					t.Errorf("SetCPUFrequencyLimitsForCpus() error = %v, wantErr = %v", err, tt.wantErr)
 				}
 			} else {
 				for _, cpu := range []string{"cpu0", "cpu1"} {
@@ -207,3 +208,195 @@ func TestSetCPUFrequencyLimits(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCpuList(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []int
+		wantErr bool
+	}{
+		{list: "", want: nil},
+		{list: "3", want: []int{3}},
+		{list: "0-3,8, 10-11", want: []int{0, 1, 2, 3, 8, 10, 11}},
+		{list: "2,1,1-2", want: []int{1, 2}},
+		{list: "3-1", wantErr: true},
+		{list: "a", wantErr: true},
+		{list: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			got, err := ParseCpuList(tt.list)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakeHybridCpus fakes two performance cores and two efficiency cores with a lower maximum frequency
+func fakeHybridCpus(t *testing.T) {
+	fakeCpuDirectory(t)
+	for i, maxFreq := range []string{"4800000", "4800000", "3600000", "3600000"} {
+		cpu := fmt.Sprintf("cpu%d", i)
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "cpuinfo_min_freq"), "800000")
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "cpuinfo_max_freq"), maxFreq)
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "scaling_min_freq"), "800000")
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "scaling_max_freq"), maxFreq)
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "scaling_cur_freq"), fmt.Sprintf("%d", 1000000*(i+1)))
+	}
+	// CPUs without cpufreq, e.g. offline ones, are ignored
+	require.NoError(t, os.MkdirAll(path.Join(cpuBasePath, "cpu4"), 0777))
+}
+
+func TestGetCPUFrequencyInfoPerCpu(t *testing.T) {
+	fakeHybridCpus(t)
+
+	infos, err := GetCPUFrequencyInfoPerCpu(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []CPUFrequencyInfo{
+		{Cpu: 0, MinFreq: 800, MaxFreq: 4800},
+		{Cpu: 1, MinFreq: 800, MaxFreq: 4800},
+		{Cpu: 2, MinFreq: 800, MaxFreq: 3600},
+		{Cpu: 3, MinFreq: 800, MaxFreq: 3600},
+	}, infos)
+
+	minFreq, maxFreq, err := GetCPUFrequencyInfo()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(800), minFreq)
+	assert.Equal(t, uint64(4800), maxFreq)
+
+	_, err = GetCPUFrequencyInfoPerCpu([]int{4})
+	assert.ErrorContains(t, err, "cpu4 does not exist or does not support frequency scaling")
+}
+
+func TestGetCurrentFrequencies(t *testing.T) {
+	fakeHybridCpus(t)
+
+	got, err := GetCurrentFrequencies([]int{1, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]uint64{1: 2000, 3: 4000}, got)
+}
+
+func TestSetCPUFrequencyLimitsForCpus(t *testing.T) {
+	fakeHybridCpus(t)
+
+	_, err := SetCPUFrequencyLimitsForCpus([]int{2, 3}, 1000, 4000)
+	assert.EqualError(t, err, "requested maximum frequency 4000 MHz is above hardware maximum 3600 MHz")
+
+	applied, err := SetCPUFrequencyLimitsForCpus(nil, 1000, 4000)
+	require.NoError(t, err)
+	assert.Equal(t, []CPUFrequencyInfo{
+		{Cpu: 0, MinFreq: 1000, MaxFreq: 4000},
+		{Cpu: 1, MinFreq: 1000, MaxFreq: 4000},
+		{Cpu: 2, MinFreq: 1000, MaxFreq: 3600},
+		{Cpu: 3, MinFreq: 1000, MaxFreq: 3600},
+	}, applied)
+
+	applied, err = SetCPUFrequencyLimitsForCpus([]int{1}, 900, 1200)
+	require.NoError(t, err)
+	assert.Equal(t, []CPUFrequencyInfo{{Cpu: 1, MinFreq: 900, MaxFreq: 1200}}, applied)
+
+	for cpu, want := range map[string][2]string{
+		"cpu0": {"1000000", "4000000"},
+		"cpu1": {"900000", "1200000"},
+		"cpu2": {"1000000", "3600000"},
+	} {
+		gotMin, err := os.ReadFile(path.Join(cpuBasePath, cpu, "cpufreq", "scaling_min_freq"))
+		require.NoError(t, err)
+		gotMax, err := os.ReadFile(path.Join(cpuBasePath, cpu, "cpufreq", "scaling_max_freq"))
+		require.NoError(t, err)
+		assert.Equal(t, want, [2]string{string(gotMin), string(gotMax)}, cpu)
+	}
+}
+
+func TestGovernors(t *testing.T) {
+	fakeHybridCpus(t)
+	for _, cpu := range []string{"cpu0", "cpu1", "cpu2", "cpu3"} {
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "scaling_governor"), "schedutil\n")
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "scaling_available_governors"), "performance powersave schedutil\n")
+	}
+	fakeCpuFile(t, path.Join("cpu3", "cpufreq", "scaling_available_governors"), "performance schedutil\n")
+
+	assert.NoError(t, CheckGovernorAvailable(nil, "performance"))
+	assert.NoError(t, CheckGovernorAvailable([]int{0, 1}, "powersave"))
+	assert.EqualError(t, CheckGovernorAvailable(nil, "powersave"), "governor powersave is not available for cpu3, available are performance, schedutil")
+
+	require.NoError(t, SetGovernors([]CPUGovernor{{Cpu: 1, Governor: "performance"}}))
+	for cpu, want := range []string{"schedutil\n", "performance", "schedutil\n", "schedutil\n"} {
+		data, err := os.ReadFile(path.Join(cpuBasePath, fmt.Sprintf("cpu%d", cpu), "cpufreq", "scaling_governor"))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+}
+
+func TestTurbo(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		wantEnabled bool
+		wantContent string
+	}{
+		{name: "intel_pstate turbo enabled", file: "intel_pstate/no_turbo", content: "0", wantEnabled: true, wantContent: "1"},
+		{name: "intel_pstate turbo disabled", file: "intel_pstate/no_turbo", content: "1", wantEnabled: false, wantContent: "1"},
+		{name: "boost enabled", file: "cpufreq/boost", content: "1", wantEnabled: true, wantContent: "0"},
+		{name: "boost disabled", file: "cpufreq/boost", content: "0", wantEnabled: false, wantContent: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCpuDirectory(t)
+			fakeCpuFile(t, tt.file, tt.content)
+
+			enabled, err := GetTurboEnabled()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEnabled, enabled)
+
+			require.NoError(t, SetTurboEnabled(false))
+			content, err := os.ReadFile(path.Join(cpuBasePath, tt.file))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(content))
+		})
+	}
+
+	fakeCpuDirectory(t)
+	_, err := GetTurboEnabled()
+	assert.ErrorContains(t, err, "turbo boost control is not supported")
+}
+
+func TestSnapshotAndRestoreScaling(t *testing.T) {
+	fakeHybridCpus(t)
+	// An administrator's custom limits, which differ from the hardware limits
+	fakeCpuFile(t, path.Join("cpu0", "cpufreq", "scaling_min_freq"), "1200000\n")
+	fakeCpuFile(t, path.Join("cpu0", "cpufreq", "scaling_max_freq"), "3900000\n")
+	for _, cpu := range []string{"cpu0", "cpu1", "cpu2", "cpu3"} {
+		fakeCpuFile(t, path.Join(cpu, "cpufreq", "scaling_governor"), "schedutil\n")
+	}
+	fakeCpuFile(t, path.Join("cpu1", "cpufreq", "scaling_governor"), "performance\n")
+
+	snapshots, err := SnapshotScaling([]int{0, 1})
+	require.NoError(t, err)
+	assert.Equal(t, []ScalingSnapshot{
+		{Cpu: 0, MinFreq: "1200000\n", MaxFreq: "3900000\n", Governor: "schedutil\n"},
+		{Cpu: 1, MinFreq: "800000", MaxFreq: "4800000", Governor: "performance\n"},
+	}, snapshots)
+
+	_, err = SetCPUFrequencyLimitsForCpus([]int{0, 1}, 800, 1000)
+	require.NoError(t, err)
+	require.NoError(t, SetGovernors([]CPUGovernor{{Cpu: 0, Governor: "powersave"}, {Cpu: 1, Governor: "powersave"}}))
+
+	require.NoError(t, RestoreScaling(snapshots))
+	for _, snapshot := range snapshots {
+		cpu := fmt.Sprintf("cpu%d", snapshot.Cpu)
+		for file, want := range map[string]string{"scaling_min_freq": snapshot.MinFreq, "scaling_max_freq": snapshot.MaxFreq, "scaling_governor": snapshot.Governor} {
+			got, err := os.ReadFile(path.Join(cpuBasePath, cpu, "cpufreq", file))
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got), "%s/%s", cpu, file)
+		}
+	}
+
+	_, err = SnapshotScaling([]int{4})
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cpuhotplug
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/steadybit/extension-host/exthost/cpufreq"
+)
+
+const (
+	cpuGlob    = "cpu[0-9]*"
+	onlineFile = "online"
+)
+
+var cpuBasePath = "/sys/devices/system/cpu"
+
+// OnlineCpus returns the CPUs currently online
+func OnlineCpus() ([]int, error) {
+	data, err := os.ReadFile(filepath.Join(cpuBasePath, onlineFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read online CPUs: %w", err)
+	}
+	return cpufreq.ParseCpuList(strings.TrimSpace(string(data)))
+}
+
+// HotpluggableCpus returns the CPUs which can be taken offline. cpu0 is never returned, as it often can't be
+// taken offline and is required for timekeeping and interrupts on many systems.
+func HotpluggableCpus() ([]int, error) {
+	dirs, err := filepath.Glob(filepath.Join(cpuBasePath, cpuGlob))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CPU directories: %w", err)
+	}
+
+	var cpus []int
+	for _, dir := range dirs {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "cpu"))
+		if err != nil || cpu == 0 {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, onlineFile)); err == nil {
+			cpus = append(cpus, cpu)
+		}
+	}
+	slices.Sort(cpus)
+	return cpus, nil
+}
+
+// SelectCpus validates the given CPUs to be taken offline, or selects the given number of online CPUs with the
+// highest numbers if none are given.
+func SelectCpus(count int, cpus []int) ([]int, error) {
+	hotpluggable, err := HotpluggableCpus()
+	if err != nil {
+		return nil, err
+	}
+	online, err := OnlineCpus()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cpus) > 0 {
+		for _, cpu := range cpus {
+			if cpu == 0 {
+				return nil, fmt.Errorf("cpu0 must not be taken offline")
+			}
+			if !slices.Contains(hotpluggable, cpu) {
+				return nil, fmt.Errorf("cpu%d does not exist or can't be taken offline", cpu)
+			}
+			if !slices.Contains(online, cpu) {
+				return nil, fmt.Errorf("cpu%d is already offline", cpu)
+			}
+		}
+		return cpus, nil
+	}
+
+	if count < 1 {
+		return nil, fmt.Errorf("at least one CPU must be taken offline, got %d", count)
+	}
+	var candidates []int
+	for _, cpu := range hotpluggable {
+		if slices.Contains(online, cpu) {
+			candidates = append(candidates, cpu)
+		}
+	}
+	if count > len(candidates) {
+		return nil, fmt.Errorf("%d CPUs requested, but only %d of the %d online CPUs can be taken offline", count, len(candidates), len(online))
+	}
+	return candidates[len(candidates)-count:], nil
+}
+
+// SetOnline brings the CPU online or takes it offline
+func SetOnline(cpu int, online bool) error {
+	if cpu == 0 {
+		return fmt.Errorf("cpu0 must not be taken offline")
+	}
+	value := "0"
+	if online {
+		value = "1"
+	}
+	if err := os.WriteFile(filepath.Join(cpuBasePath, fmt.Sprintf("cpu%d", cpu), onlineFile), []byte(value), 0644); err != nil {
+		if online {
+			return fmt.Errorf("failed to bring cpu%d online: %w", cpu, err)
+		}
+		return fmt.Errorf("failed to take cpu%d offline: %w", cpu, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package cpuhotplug
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCpus fakes a host with cpu0 to cpu3 and cpu3 being offline. cpu0 has no online file like on most x86 hosts.
+func fakeCpus(t *testing.T) {
+	oldBasePath := cpuBasePath
+	cpuBasePath = t.TempDir()
+	t.Cleanup(func() {
+		cpuBasePath = oldBasePath
+	})
+
+	require.NoError(t, os.WriteFile(path.Join(cpuBasePath, "online"), []byte("0-2\n"), 0666))
+	for i := 0; i < 4; i++ {
+		dir := path.Join(cpuBasePath, fmt.Sprintf("cpu%d", i))
+		require.NoError(t, os.MkdirAll(dir, 0777))
+		if i > 0 {
+			online := "1"
+			if i == 3 {
+				online = "0"
+			}
+			require.NoError(t, os.WriteFile(path.Join(dir, "online"), []byte(online), 0666))
+		}
+	}
+	require.NoError(t, os.MkdirAll(path.Join(cpuBasePath, "cpufreq"), 0777))
+}
+
+func TestHotpluggableCpus(t *testing.T) {
+	fakeCpus(t)
+
+	cpus, err := HotpluggableCpus()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, cpus)
+
+	online, err := OnlineCpus()
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, online)
+}
+
+func TestSelectCpus(t *testing.T) {
+	fakeCpus(t)
+
+	tests := []struct {
+		name    string
+		count   int
+		cpus    []int
+		want    []int
+		wantErr string
+	}{
+		{name: "highest online cpu", count: 1, want: []int{2}},
+		{name: "all hotpluggable online cpus", count: 2, want: []int{1, 2}},
+		{name: "too many cpus", count: 3, wantErr: "3 CPUs requested, but only 2 of the 3 online CPUs can be taken offline"},
+		{name: "no cpu", count: 0, wantErr: "at least one CPU must be taken offline"},
+		{name: "listed cpus", cpus: []int{1}, want: []int{1}},
+		{name: "cpu0", cpus: []int{0, 1}, wantErr: "cpu0 must not be taken offline"},
+		{name: "offline cpu", cpus: []int{3}, wantErr: "cpu3 is already offline"},
+		{name: "unknown cpu", cpus: []int{7}, wantErr: "cpu7 does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectCpus(tt.count, tt.cpus)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSetOnline(t *testing.T) {
+	fakeCpus(t)
+
+	require.NoError(t, SetOnline(2, false))
+	content, err := os.ReadFile(path.Join(cpuBasePath, "cpu2", "online"))
+	require.NoError(t, err)
+	assert.Equal(t, "0", string(content))
+
+	require.NoError(t, SetOnline(2, true))
+	content, err = os.ReadFile(path.Join(cpuBasePath, "cpu2", "online"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(content))
+
+	assert.ErrorContains(t, SetOnline(0, false), "cpu0 must not be taken offline")
+	assert.ErrorContains(t, SetOnline(9, false), "failed to take cpu9 offline")
+}
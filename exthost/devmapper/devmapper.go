@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package devmapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-host/exthost/hostns"
+)
+
+var sysBasePath = "/sys"
+
+var runDmsetup = func(ctx context.Context, arg ...string) (string, error) {
+	return hostns.Run(ctx, "dmsetup", arg...)
+}
+
+// Target is a single line of a device-mapper table.
+type Target struct {
+	Start  uint64
+	Length uint64
+	Type   string
+	Args   []string
+}
+
+func (t Target) String() string {
+	return strings.Join(append([]string{strconv.FormatUint(t.Start, 10), strconv.FormatUint(t.Length, 10), t.Type}, t.Args...), " ")
+}
+
+// DeviceName returns the device-mapper name of the block device or an error if the device is not a device-mapper device.
+func DeviceName(major, minor uint32) (string, error) {
+	data, err := os.ReadFile(filepath.Join(sysBasePath, "dev", "block", fmt.Sprintf("%d:%d", major, minor), "dm", "name"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("device %d:%d is not a device-mapper device", major, minor)
+		}
+		return "", fmt.Errorf("failed to read device-mapper name of %d:%d: %w", major, minor, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ReadTable returns the live table of the given device-mapper device.
+func ReadTable(ctx context.Context, name string) ([]Target, error) {
+	out, err := runDmsetup(ctx, "table", name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTable(out)
+}
+
+func ParseTable(s string) ([]Target, error) {
+	var table []Target
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("invalid device-mapper table line: %s", line)
+		}
+		start, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start sector in device-mapper table line: %s", line)
+		}
+		length, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid length in device-mapper table line: %s", line)
+		}
+		table = append(table, Target{Start: start, Length: length, Type: fields[2], Args: fields[3:]})
+	}
+	if len(table) == 0 {
+		return nil, errors.New("empty device-mapper table")
+	}
+	return table, nil
+}
+
+// linearTarget returns the only target of the table, if it is a linear mapping.
+// Tables with multiple segments or other target types cannot be wrapped.
+func linearTarget(table []Target) (Target, error) {
+	if len(table) != 1 {
+		return Target{}, fmt.Errorf("device-mapper tables with %d segments are not supported", len(table))
+	}
+	if table[0].Type != "linear" || len(table[0].Args) != 2 {
+		return Target{}, fmt.Errorf("device-mapper target type %s is not supported, only linear mappings are", table[0].Type)
+	}
+	return table[0], nil
+}
+
+// DelayTable wraps a linear table with a delay target, delaying all reads and writes.
+func DelayTable(table []Target, delay time.Duration) ([]Target, error) {
+	linear, err := linearTarget(table)
+	if err != nil {
+		return nil, err
+	}
+	return []Target{{
+		Start:  linear.Start,
+		Length: linear.Length,
+		Type:   "delay",
+		Args:   []string{linear.Args[0], linear.Args[1], strconv.FormatInt(delay.Milliseconds(), 10)},
+	}}, nil
+}
+
+// FlakeyTable wraps a linear table with a flakey target, failing all I/O with EIO during the down intervals.
+func FlakeyTable(table []Target, up, down time.Duration) ([]Target, error) {
+	linear, err := linearTarget(table)
+	if err != nil {
+		return nil, err
+	}
+	if up+down < time.Second {
+		return nil, errors.New("the sum of up and down interval must be at least 1s")
+	}
+	return []Target{{
+		Start:  linear.Start,
+		Length: linear.Length,
+		Type:   "flakey",
+		Args:   []string{linear.Args[0], linear.Args[1], strconv.FormatInt(int64(up.Seconds()), 10), strconv.FormatInt(int64(down.Seconds()), 10)},
+	}}, nil
+}
+
+// LoadTable replaces the live table of the device. The device is suspended only for the swap of the tables.
+func LoadTable(ctx context.Context, name string, table []Target) error {
+	lines := make([]string, 0, len(table))
+	for _, t := range table {
+		lines = append(lines, t.String())
+	}
+	log.Info().Str("device", name).Strs("table", lines).Msg("loading device-mapper table")
+
+	if _, err := runDmsetup(ctx, "reload", name, "--table", strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	if _, err := runDmsetup(ctx, "suspend", name); err != nil {
+		_, _ = runDmsetup(ctx, "clear", name)
+		return err
+	}
+	if _, err := runDmsetup(ctx, "resume", name); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package devmapper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTable(t *testing.T) {
+	table, err := ParseTable("0 41943040 linear 8:2 2048\n")
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{Start: 0, Length: 41943040, Type: "linear", Args: []string{"8:2", "2048"}}}, table)
+	assert.Equal(t, "0 41943040 linear 8:2 2048", table[0].String())
+
+	_, err = ParseTable("")
+	assert.Error(t, err)
+	_, err = ParseTable("0 abc linear 8:2 2048")
+	assert.Error(t, err)
+}
+
+func TestDelayTable(t *testing.T) {
+	table, _ := ParseTable("0 41943040 linear 8:2 2048")
+
+	delayed, err := DelayTable(table, 150*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "0 41943040 delay 8:2 2048 150", delayed[0].String())
+
+	striped, _ := ParseTable("0 1024 striped 2 128 8:2 0 8:3 0")
+	_, err = DelayTable(striped, 150*time.Millisecond)
+	assert.Error(t, err)
+
+	multi, _ := ParseTable("0 1024 linear 8:2 0\n1024 1024 linear 8:3 0")
+	_, err = DelayTable(multi, 150*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestFlakeyTable(t *testing.T) {
+	table, _ := ParseTable("0 41943040 linear 8:2 2048")
+
+	flakey, err := FlakeyTable(table, 0, 1*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "0 41943040 flakey 8:2 2048 0 1", flakey[0].String())
+
+	_, err = FlakeyTable(table, 0, 0)
+	assert.Error(t, err)
+}
+
+func TestLoadTable(t *testing.T) {
+	var calls [][]string
+	oldRunDmsetup := runDmsetup
+	t.Cleanup(func() {
+		runDmsetup = oldRunDmsetup
+	})
+	runDmsetup = func(ctx context.Context, arg ...string) (string, error) {
+		calls = append(calls, arg)
+		return "", nil
+	}
+
+	table, _ := ParseTable("0 41943040 delay 8:2 2048 150")
+	require.NoError(t, LoadTable(context.Background(), "vg-root", table))
+	assert.Equal(t, [][]string{
+		{"reload", "vg-root", "--table", "0 41943040 delay 8:2 2048 150"},
+		{"suspend", "vg-root"},
+		{"resume", "vg-root"},
+	}, calls)
+}
+
+func TestDeviceName(t *testing.T) {
+	oldBasePath := sysBasePath
+	sysBasePath = t.TempDir()
+	t.Cleanup(func() {
+		sysBasePath = oldBasePath
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(sysBasePath, "dev", "block", "253:0", "dm"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sysBasePath, "dev", "block", "253:0", "dm", "name"), []byte("vg-root\n"), 0644))
+
+	name, err := DeviceName(253, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "vg-root", name)
+
+	_, err = DeviceName(8, 1)
+	assert.ErrorContains(t, err, "not a device-mapper device")
+}
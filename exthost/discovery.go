@@ -16,6 +16,7 @@ import (
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-host/config"
 	"github.com/steadybit/extension-host/exthost/cpufreq"
+	"github.com/steadybit/extension-host/exthost/mounts"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
 )
@@ -137,6 +138,12 @@ func (d *hostDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescri
 				One:   "Maximum CPU Frequency (MHz)",
 				Other: "Maximum CPU Frequencies (MHz)",
 			},
+		}, {
+			Attribute: "host.mount_point",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Mount Point",
+				Other: "Mount Points",
+			},
 		},
 	}
 }
@@ -191,6 +198,12 @@ func (d *hostDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_ap
 		log.Trace().Err(err).Msg("Failed to get CPU frequency info")
 	}
 
+	if hostMounts, err := mounts.ReadHostMountInfo(); err == nil {
+		target.Attributes["host.mount_point"] = mounts.DiskMountPoints(hostMounts)
+	} else {
+		log.Trace().Err(err).Msg("Failed to get mount points")
+	}
+
 	for key, value := range getEnvironmentVariables() {
 		target.Attributes["host.env."+key] = []string{value}
 	}
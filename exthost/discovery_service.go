@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package exthost
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-host/config"
+	"github.com/steadybit/extension-host/exthost/systemd"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type serviceDiscovery struct {
+}
+
+var (
+	_ discovery_kit_sdk.TargetDescriber    = (*serviceDiscovery)(nil)
+	_ discovery_kit_sdk.AttributeDescriber = (*serviceDiscovery)(nil)
+
+	listServices  = systemd.ListServices
+	systemdBooted = systemd.Booted
+)
+
+func NewServiceDiscovery() discovery_kit_sdk.TargetDiscovery {
+	discovery := &serviceDiscovery{}
+	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
+		discovery_kit_sdk.WithRefreshTargetsNow(),
+		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 30*time.Second),
+	)
+}
+
+func (d *serviceDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
+	return discovery_kit_api.DiscoveryDescription{
+		Id: serviceTargetID,
+		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
+			CallInterval: extutil.Ptr("30s"),
+		},
+	}
+}
+
+func (d *serviceDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
+	return discovery_kit_api.TargetDescription{
+		Id:      serviceTargetID,
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    extutil.Ptr(targetIcon),
+
+		// Labels used in the UI
+		Label: discovery_kit_api.PluralLabel{One: "Systemd Service", Other: "Systemd Services"},
+
+		// Category for the targets to appear in
+		Category: extutil.Ptr("basic"),
+
+		// Specify attributes shown in table columns and to be used for sorting
+		Table: discovery_kit_api.Table{
+			Columns: []discovery_kit_api.Column{
+				{Attribute: "host.service.name"},
+				{Attribute: "host.hostname"},
+				{Attribute: "host.service.state"},
+			},
+			OrderBy: []discovery_kit_api.OrderBy{
+				{
+					Attribute: "host.service.name",
+					Direction: "ASC",
+				},
+			},
+		},
+	}
+}
+
+func (d *serviceDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescription {
+	return []discovery_kit_api.AttributeDescription{
+		{
+			Attribute: "host.service.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Service Name",
+				Other: "Service Names",
+			},
+		}, {
+			Attribute: "host.service.description",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Service Description",
+				Other: "Service Descriptions",
+			},
+		}, {
+			Attribute: "host.service.state",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Service State",
+				Other: "Service States",
+			},
+		}, {
+			Attribute: "host.service.cgroup",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Service Cgroup",
+				Other: "Service Cgroups",
+			},
+		}, {
+			Attribute: "host.service.memory.accounting",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Service Memory Accounting",
+				Other: "Service Memory Accountings",
+			},
+		}, {
+			Attribute: "host.service.cpu.accounting",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Service CPU Accounting",
+				Other: "Service CPU Accountings",
+			},
+		},
+	}
+}
+
+func (d *serviceDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	if !systemdBooted() {
+		log.Trace().Msg("Host doesn't run systemd, no services to discover")
+		return []discovery_kit_api.Target{}, nil
+	}
+
+	hostname, _ := osHostname()
+	services, err := listServices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list systemd services: %w", err)
+	}
+
+	targets := make([]discovery_kit_api.Target, 0, len(services))
+	for _, service := range services {
+		target := discovery_kit_api.Target{
+			Id:         fmt.Sprintf("%s/%s", hostname, service.Name),
+			TargetType: serviceTargetID,
+			Label:      service.Name,
+			Attributes: map[string][]string{
+				"host.hostname":                  {hostname},
+				"host.service.name":              {service.Name},
+				"host.service.state":             {service.ActiveState},
+				"host.service.memory.accounting": {strconv.FormatBool(service.MemoryAccounting)},
+				"host.service.cpu.accounting":    {strconv.FormatBool(service.CPUAccounting)},
+			},
+		}
+		if service.Description != "" {
+			target.Attributes["host.service.description"] = []string{service.Description}
+		}
+		if service.ControlGroup != "" {
+			target.Attributes["host.service.cgroup"] = []string{service.ControlGroup}
+		}
+		for key, value := range getLabels() {
+			target.Attributes["host.label."+key] = []string{value}
+		}
+		targets = append(targets, target)
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(targets, config.Config.DiscoveryAttributesExcludesService), nil
+}
@@ -0,0 +1,76 @@
+package exthost
+
+import (
+	"context"
+	"errors"
+	"github.com/steadybit/extension-host/config"
+	"github.com/steadybit/extension-host/exthost/systemd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_DiscoverServiceTargets(t *testing.T) {
+	//given
+	osHostname = func() (string, error) {
+		return "myhostname", nil
+	}
+	oldListServices, oldSystemdBooted := listServices, systemdBooted
+	t.Cleanup(func() {
+		listServices, systemdBooted = oldListServices, oldSystemdBooted
+		config.Config.DiscoveryAttributesExcludesService = nil
+	})
+	systemdBooted = func() bool { return true }
+	listServices = func(ctx context.Context) ([]systemd.Service, error) {
+		return []systemd.Service{
+			{
+				Name:             "nginx.service",
+				Description:      "A high performance web server",
+				ActiveState:      "active",
+				ControlGroup:     "/system.slice/nginx.service",
+				MemoryAccounting: true,
+			},
+			{
+				Name:        "backup.service",
+				ActiveState: "failed",
+			},
+		}, nil
+	}
+	config.Config.DiscoveryAttributesExcludesService = []string{"host.service.cgroup"}
+
+	//when
+	targets, err := (&serviceDiscovery{}).DiscoverTargets(context.Background())
+
+	//then
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "myhostname/nginx.service", targets[0].Id)
+	assert.Equal(t, "nginx.service", targets[0].Label)
+	assert.Equal(t, serviceTargetID, targets[0].TargetType)
+	attributes := targets[0].Attributes
+	assert.Equal(t, []string{"myhostname"}, attributes["host.hostname"])
+	assert.Equal(t, []string{"nginx.service"}, attributes["host.service.name"])
+	assert.Equal(t, []string{"active"}, attributes["host.service.state"])
+	assert.Equal(t, []string{"true"}, attributes["host.service.memory.accounting"])
+	assert.Equal(t, []string{"false"}, attributes["host.service.cpu.accounting"])
+	assert.NotContains(t, attributes, "host.service.cgroup")
+
+	assert.Equal(t, []string{"failed"}, targets[1].Attributes["host.service.state"])
+	assert.NotContains(t, targets[1].Attributes, "host.service.description")
+}
+
+func Test_DiscoverServiceTargetsWithoutSystemd(t *testing.T) {
+	oldListServices, oldSystemdBooted := listServices, systemdBooted
+	t.Cleanup(func() {
+		listServices, systemdBooted = oldListServices, oldSystemdBooted
+	})
+	systemdBooted = func() bool { return false }
+	listServices = func(ctx context.Context) ([]systemd.Service, error) {
+		return nil, errors.New("systemctl: command not found")
+	}
+
+	targets, err := (&serviceDiscovery{}).DiscoverTargets(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, targets)
+}
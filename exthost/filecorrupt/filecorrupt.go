@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package filecorrupt
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-host/exthost/hostns"
+)
+
+type Mode string
+
+const (
+	ModeDelete   Mode = "DELETE"
+	ModeTruncate Mode = "TRUNCATE"
+	ModeCorrupt  Mode = "CORRUPT"
+)
+
+// Backup is a copy of a host file, it keeps owner, mode and timestamps of the original.
+type Backup struct {
+	Path       string
+	BackupPath string
+	Size       int64
+}
+
+// runHostCommand runs as root in the host's namespaces, the extension's user may neither chown the copies nor replace files of other users in sticky directories
+var runHostCommand = hostns.Run
+
+// BackupPath returns the path of the backup, a hidden file next to the original to be able to restore it by renaming.
+func BackupPath(path string, executionId uuid.UUID) string {
+	return filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.steadybit-%s", filepath.Base(path), executionId))
+}
+
+// Create copies the regular host file at path to backupPath, keeping owner, mode and timestamps.
+func Create(ctx context.Context, path, backupPath string) (Backup, error) {
+	fi, err := os.Lstat(hostfs.Path(path))
+	if err != nil {
+		return Backup{}, err
+	}
+	if !fi.Mode().IsRegular() {
+		return Backup{}, fmt.Errorf("%s is not a regular file", path)
+	}
+	b := Backup{
+		Path:       path,
+		BackupPath: backupPath,
+		Size:       fi.Size(),
+	}
+
+	if usage, err := hostfs.Statfs(filepath.Dir(path)); err == nil && usage.Available < uint64(b.Size) {
+		return Backup{}, fmt.Errorf("not enough space to back up %s: %d bytes needed, %d bytes available", path, b.Size, usage.Available)
+	}
+	if _, err := os.Lstat(hostfs.Path(backupPath)); !errors.Is(err, os.ErrNotExist) {
+		return Backup{}, fmt.Errorf("failed to create backup of %s: %s already exists", path, backupPath)
+	}
+
+	if _, err := runHostCommand(ctx, "cp", "-a", "--", path, backupPath); err != nil {
+		_, _ = runHostCommand(ctx, "rm", "-f", "--", backupPath)
+		return Backup{}, fmt.Errorf("failed to write backup of %s: %w", path, err)
+	}
+	if err := syncFile(backupPath); err != nil {
+		_, _ = runHostCommand(ctx, "rm", "-f", "--", backupPath)
+		return Backup{}, fmt.Errorf("failed to write backup of %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Apply deletes, truncates or overwrites the original file with random bytes of the same size.
+func Apply(ctx context.Context, b Backup, mode Mode) error {
+	path := hostfs.Path(b.Path)
+	switch mode {
+	case ModeDelete:
+		_, err := runHostCommand(ctx, "rm", "-f", "--", b.Path)
+		return err
+	case ModeTruncate:
+		return os.Truncate(path, 0)
+	case ModeCorrupt:
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		if _, err := io.CopyN(f, rand.Reader, b.Size); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	default:
+		return fmt.Errorf("unsupported mode %s", mode)
+	}
+}
+
+// Restore writes the backup's content back to the original and removes the backup. If the original still exists,
+// its content is replaced in place to keep the inode, otherwise the backup is renamed. Restoring twice is a no-op.
+func Restore(ctx context.Context, b Backup) error {
+	if _, err := os.Lstat(hostfs.Path(b.BackupPath)); errors.Is(err, os.ErrNotExist) {
+		if _, err := os.Stat(hostfs.Path(b.Path)); err != nil {
+			return fmt.Errorf("neither %s nor its backup %s exist", b.Path, b.BackupPath)
+		}
+		return nil
+	}
+
+	fi, err := os.Lstat(hostfs.Path(b.Path))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if _, err := runHostCommand(ctx, "mv", "--", b.BackupPath, b.Path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", b.Path, err)
+		}
+		return nil
+	case err != nil:
+		return err
+	case !fi.Mode().IsRegular():
+		return fmt.Errorf("%s was replaced by something else than a regular file, the original is kept at %s", b.Path, b.BackupPath)
+	}
+
+	// cp truncates the existing file instead of replacing it, -a restores owner, mode and timestamps afterwards
+	if _, err := runHostCommand(ctx, "cp", "-a", "--", b.BackupPath, b.Path); err != nil {
+		return fmt.Errorf("failed to restore %s, the original is kept at %s: %w", b.Path, b.BackupPath, err)
+	}
+	if err := syncFile(b.Path); err != nil {
+		return fmt.Errorf("failed to restore %s, the original is kept at %s: %w", b.Path, b.BackupPath, err)
+	}
+	if _, err := runHostCommand(ctx, "rm", "-f", "--", b.BackupPath); err != nil {
+		return fmt.Errorf("failed to remove the backup %s: %w", b.BackupPath, err)
+	}
+	return nil
+}
+
+// syncFile flushes the host file to disk, so neither the backup nor the restored original is lost on a crash.
+func syncFile(hostPath string) error {
+	f, err := os.Open(hostfs.Path(hostPath))
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package filecorrupt
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeHostRoot(t *testing.T) {
+	oldRootPath := hostfs.RootPath
+	oldRunHostCommand := runHostCommand
+	hostfs.RootPath = t.TempDir()
+	// runs the command locally with the host paths mapped into the fake root
+	runHostCommand = func(ctx context.Context, name string, arg ...string) (string, error) {
+		mapped := make([]string, len(arg))
+		for i, a := range arg {
+			if strings.HasPrefix(a, "/") {
+				a = hostfs.Path(a)
+			}
+			mapped[i] = a
+		}
+		out, err := exec.CommandContext(ctx, name, mapped...).CombinedOutput()
+		return string(out), err
+	}
+	t.Cleanup(func() {
+		hostfs.RootPath = oldRootPath
+		runHostCommand = oldRunHostCommand
+	})
+}
+
+func writeOriginal(t *testing.T, path string, content []byte) time.Time {
+	require.NoError(t, os.WriteFile(hostfs.Path(path), content, 0640))
+	require.NoError(t, os.Chmod(hostfs.Path(path), 0640))
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)
+	require.NoError(t, os.Chtimes(hostfs.Path(path), mtime, mtime))
+	return mtime
+}
+
+func assertOriginal(t *testing.T, path string, content []byte, mtime time.Time) {
+	got, err := os.ReadFile(hostfs.Path(path))
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	fi, err := os.Stat(hostfs.Path(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+	assert.True(t, mtime.Equal(fi.ModTime()), "mtime %s != %s", mtime, fi.ModTime())
+}
+
+func TestBackupPath(t *testing.T) {
+	id := uuid.MustParse("e3b2b0f6-7a3c-4c09-8d2b-6a5b5f1c0e11")
+	assert.Equal(t, "/etc/app/.app.conf.steadybit-e3b2b0f6-7a3c-4c09-8d2b-6a5b5f1c0e11", BackupPath("/etc/app/app.conf", id))
+}
+
+func TestApplyAndRestore(t *testing.T) {
+	content := []byte("key=value\n")
+	for _, mode := range []Mode{ModeDelete, ModeTruncate, ModeCorrupt} {
+		t.Run(string(mode), func(t *testing.T) {
+			fakeHostRoot(t)
+			mtime := writeOriginal(t, "/app.conf", content)
+
+			b, err := Create(context.Background(), "/app.conf", BackupPath("/app.conf", uuid.New()))
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(content)), b.Size)
+			assertOriginal(t, b.BackupPath, content, mtime)
+
+			var inode uint64
+			if fi, err := os.Stat(hostfs.Path("/app.conf")); err == nil {
+				inode = fi.Sys().(*syscall.Stat_t).Ino
+			}
+
+			require.NoError(t, Apply(context.Background(), b, mode))
+			got, err := os.ReadFile(hostfs.Path("/app.conf"))
+			switch mode {
+			case ModeDelete:
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			case ModeTruncate:
+				require.NoError(t, err)
+				assert.Empty(t, got)
+			case ModeCorrupt:
+				require.NoError(t, err)
+				assert.Len(t, got, len(content))
+				assert.False(t, bytes.Equal(content, got))
+			}
+
+			require.NoError(t, Restore(context.Background(), b))
+			assertOriginal(t, "/app.conf", content, mtime)
+			_, err = os.Stat(hostfs.Path(b.BackupPath))
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			if mode != ModeDelete {
+				fi, err := os.Stat(hostfs.Path("/app.conf"))
+				require.NoError(t, err)
+				assert.Equal(t, inode, fi.Sys().(*syscall.Stat_t).Ino, "restored in place")
+			}
+
+			require.NoError(t, Restore(context.Background(), b), "restoring twice")
+		})
+	}
+}
+
+func TestRestoreWithoutBackupAndOriginal(t *testing.T) {
+	fakeHostRoot(t)
+	err := Restore(context.Background(), Backup{Path: "/app.conf", BackupPath: "/.app.conf.bak"})
+	assert.ErrorContains(t, err, "neither /app.conf nor its backup")
+}
+
+func TestCreateRefusesNonRegularFiles(t *testing.T) {
+	fakeHostRoot(t)
+	require.NoError(t, os.Mkdir(hostfs.Path("/dir"), 0755))
+	_, err := Create(context.Background(), "/dir", "/.dir.bak")
+	assert.ErrorContains(t, err, "is not a regular file")
+}
+
+func TestCreateRefusesExistingBackup(t *testing.T) {
+	fakeHostRoot(t)
+	writeOriginal(t, "/app.conf", []byte("key=value\n"))
+	require.NoError(t, os.WriteFile(hostfs.Path("/.app.conf.bak"), []byte("other"), 0600))
+
+	_, err := Create(context.Background(), "/app.conf", "/.app.conf.bak")
+	assert.ErrorContains(t, err, "already exists")
+	got, err := os.ReadFile(hostfs.Path("/.app.conf.bak"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other"), got)
+}
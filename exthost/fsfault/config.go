@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package fsfault
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Operations that faults can be injected into.
+const (
+	OpOpen    = "open"
+	OpCreate  = "create"
+	OpRead    = "read"
+	OpWrite   = "write"
+	OpFsync   = "fsync"
+	OpStat    = "stat"
+	OpReaddir = "readdir"
+	OpMkdir   = "mkdir"
+	OpRmdir   = "rmdir"
+	OpUnlink  = "unlink"
+	OpRename  = "rename"
+)
+
+var Operations = []string{OpOpen, OpCreate, OpRead, OpWrite, OpFsync, OpStat, OpReaddir, OpMkdir, OpRmdir, OpUnlink, OpRename}
+
+// Errors that can be injected, an empty name only injects latency.
+var Errors = map[string]syscall.Errno{
+	"EIO":    syscall.EIO,
+	"ENOSPC": syscall.ENOSPC,
+	"EACCES": syscall.EACCES,
+	"EROFS":  syscall.EROFS,
+}
+
+// Config describes which faults are injected into the file operations.
+type Config struct {
+	Error       string
+	Probability int
+	Latency     time.Duration
+	Operations  []string
+}
+
+func (c Config) Validate() error {
+	if _, ok := Errors[c.Error]; !ok && c.Error != "" {
+		return fmt.Errorf("unsupported error %s", c.Error)
+	}
+	if c.Error == "" && c.Latency <= 0 {
+		return errors.New("either an error or a latency is required")
+	}
+	if c.Probability < 1 || c.Probability > 100 {
+		return fmt.Errorf("probability must be between 1 and 100, got %d", c.Probability)
+	}
+	for _, op := range c.Operations {
+		if !slices.Contains(Operations, op) {
+			return fmt.Errorf("unsupported operation %s", op)
+		}
+	}
+	return nil
+}
+
+// Args returns the command line arguments to pass the config to the fault injection process.
+func (c Config) Args() []string {
+	return []string{
+		"--error", c.Error,
+		"--probability", strconv.Itoa(c.Probability),
+		"--latency", c.Latency.String(),
+		"--operations", strings.Join(c.Operations, ","),
+	}
+}
+
+func parseArgs(args []string) (string, Config, error) {
+	var c Config
+	var dir, operations string
+	flags := flag.NewFlagSet(Subcommand, flag.ContinueOnError)
+	flags.StringVar(&dir, "dir", "", "directory to inject faults into")
+	flags.StringVar(&c.Error, "error", "", "error to inject")
+	flags.IntVar(&c.Probability, "probability", 100, "probability in percent")
+	flags.DurationVar(&c.Latency, "latency", 0, "latency to inject")
+	flags.StringVar(&operations, "operations", "", "comma separated operations to affect, all if empty")
+	if err := flags.Parse(args); err != nil {
+		return "", c, err
+	}
+	if operations != "" {
+		c.Operations = strings.Split(operations, ",")
+	}
+	if dir == "" {
+		return "", c, errors.New("--dir is required")
+	}
+	return dir, c, c.Validate()
+}
+
+type injector struct {
+	errno       syscall.Errno
+	probability int
+	latency     time.Duration
+	operations  map[string]bool
+	random      func() int
+}
+
+func newInjector(c Config) *injector {
+	i := &injector{
+		errno:       Errors[c.Error],
+		probability: c.Probability,
+		latency:     c.Latency,
+		random:      func() int { return rand.IntN(100) },
+	}
+	if len(c.Operations) > 0 {
+		i.operations = make(map[string]bool, len(c.Operations))
+		for _, op := range c.Operations {
+			i.operations[op] = true
+		}
+	}
+	return i
+}
+
+// inject delays the operation and returns the error to fail it with, or 0 to let it pass.
+func (i *injector) inject(ctx context.Context, op string) syscall.Errno {
+	if i.operations != nil && !i.operations[op] {
+		return 0
+	}
+	if i.random() >= i.probability {
+		return 0
+	}
+	if i.latency > 0 {
+		select {
+		case <-time.After(i.latency):
+		case <-ctx.Done():
+			return syscall.EINTR
+		}
+	}
+	return i.errno
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package fsfault
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{Error: "EIO", Probability: 100}.Validate())
+	assert.NoError(t, Config{Latency: time.Second, Probability: 10, Operations: []string{OpRead, OpWrite}}.Validate())
+	assert.EqualError(t, Config{Error: "EPERM", Probability: 100}.Validate(), "unsupported error EPERM")
+	assert.EqualError(t, Config{Probability: 100}.Validate(), "either an error or a latency is required")
+	assert.EqualError(t, Config{Error: "EIO", Probability: 0}.Validate(), "probability must be between 1 and 100, got 0")
+	assert.EqualError(t, Config{Error: "EIO", Probability: 50, Operations: []string{"chmod"}}.Validate(), "unsupported operation chmod")
+}
+
+func Test_parseArgs(t *testing.T) {
+	c := Config{Error: "ENOSPC", Probability: 25, Latency: 50 * time.Millisecond, Operations: []string{OpWrite, OpCreate}}
+
+	dir, parsed, err := parseArgs(append([]string{"--dir", "/var/lib/data"}, c.Args()...))
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/data", dir)
+	assert.Equal(t, c, parsed)
+
+	_, _, err = parseArgs(c.Args())
+	assert.EqualError(t, err, "--dir is required")
+}
+
+func Test_injector(t *testing.T) {
+	i := newInjector(Config{Error: "EIO", Probability: 30, Operations: []string{OpRead}})
+
+	i.random = func() int { return 29 }
+	assert.Equal(t, syscall.EIO, i.inject(context.Background(), OpRead))
+	assert.Equal(t, syscall.Errno(0), i.inject(context.Background(), OpWrite))
+
+	i.random = func() int { return 30 }
+	assert.Equal(t, syscall.Errno(0), i.inject(context.Background(), OpRead))
+
+	latency := newInjector(Config{Latency: 20 * time.Millisecond, Probability: 100})
+	start := time.Now()
+	assert.Equal(t, syscall.Errno(0), latency.inject(context.Background(), OpStat))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, syscall.EINTR, newInjector(Config{Latency: time.Hour, Probability: 100}).inject(ctx, OpStat))
+}
@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package fsfault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/unix"
+)
+
+// Subcommand is the argument the extension is started with to serve the fault injecting filesystem.
+const Subcommand = "fsfault"
+
+// FsType is the type of the fault injecting filesystem as listed in the mountinfo.
+const FsType = "fuse.steadybit-fsfault"
+
+// faultNode is a loopback node injecting faults before delegating the operations.
+type faultNode struct {
+	*fs.LoopbackNode
+	injector *injector
+}
+
+var (
+	_ fs.NodeWrapChilder = (*faultNode)(nil)
+	_ fs.NodeOpener      = (*faultNode)(nil)
+	_ fs.NodeCreater     = (*faultNode)(nil)
+	_ fs.NodeReader      = (*faultNode)(nil)
+	_ fs.NodeWriter      = (*faultNode)(nil)
+	_ fs.NodeFsyncer     = (*faultNode)(nil)
+	_ fs.NodeGetattrer   = (*faultNode)(nil)
+	_ fs.NodeReaddirer   = (*faultNode)(nil)
+	_ fs.NodeMkdirer     = (*faultNode)(nil)
+	_ fs.NodeRmdirer     = (*faultNode)(nil)
+	_ fs.NodeUnlinker    = (*faultNode)(nil)
+	_ fs.NodeRenamer     = (*faultNode)(nil)
+)
+
+// faultFile disables the kernel passthrough of loopback files, the reads and writes have to reach the faultNode.
+type faultFile struct {
+	*fs.LoopbackFile
+}
+
+func (f *faultFile) PassthroughFd() (int, bool) {
+	return 0, false
+}
+
+func wrapFile(fh fs.FileHandle) fs.FileHandle {
+	if lf, ok := fh.(*fs.LoopbackFile); ok {
+		return &faultFile{LoopbackFile: lf}
+	}
+	return fh
+}
+
+func (n *faultNode) WrapChild(_ context.Context, ops fs.InodeEmbedder) fs.InodeEmbedder {
+	return &faultNode{LoopbackNode: ops.(*fs.LoopbackNode), injector: n.injector}
+}
+
+func (n *faultNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if errno := n.injector.inject(ctx, OpOpen); errno != 0 {
+		return nil, 0, errno
+	}
+	fh, fuseFlags, errno := n.LoopbackNode.Open(ctx, flags)
+	return wrapFile(fh), fuseFlags, errno
+}
+
+func (n *faultNode) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	if errno := n.injector.inject(ctx, OpCreate); errno != 0 {
+		return nil, nil, 0, errno
+	}
+	inode, fh, fuseFlags, errno := n.LoopbackNode.Create(ctx, name, flags, mode, out)
+	return inode, wrapFile(fh), fuseFlags, errno
+}
+
+func (n *faultNode) Read(ctx context.Context, f fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if errno := n.injector.inject(ctx, OpRead); errno != 0 {
+		return nil, errno
+	}
+	if r, ok := f.(fs.FileReader); ok {
+		return r.Read(ctx, dest, off)
+	}
+	return nil, syscall.ENOTSUP
+}
+
+func (n *faultNode) Write(ctx context.Context, f fs.FileHandle, data []byte, off int64) (uint32, syscall.Errno) {
+	if errno := n.injector.inject(ctx, OpWrite); errno != 0 {
+		return 0, errno
+	}
+	if w, ok := f.(fs.FileWriter); ok {
+		return w.Write(ctx, data, off)
+	}
+	return 0, syscall.ENOTSUP
+}
+
+func (n *faultNode) Fsync(ctx context.Context, f fs.FileHandle, flags uint32) syscall.Errno {
+	if errno := n.injector.inject(ctx, OpFsync); errno != 0 {
+		return errno
+	}
+	if s, ok := f.(fs.FileFsyncer); ok {
+		return s.Fsync(ctx, flags)
+	}
+	return syscall.ENOTSUP
+}
+
+func (n *faultNode) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	if errno := n.injector.inject(ctx, OpStat); errno != 0 {
+		return errno
+	}
+	return n.LoopbackNode.Getattr(ctx, f, out)
+}
+
+func (n *faultNode) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	if errno := n.injector.inject(ctx, OpReaddir); errno != 0 {
+		return nil, errno
+	}
+	return n.LoopbackNode.Readdir(ctx)
+}
+
+func (n *faultNode) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	if errno := n.injector.inject(ctx, OpMkdir); errno != 0 {
+		return nil, errno
+	}
+	return n.LoopbackNode.Mkdir(ctx, name, mode, out)
+}
+
+func (n *faultNode) Rmdir(ctx context.Context, name string) syscall.Errno {
+	if errno := n.injector.inject(ctx, OpRmdir); errno != 0 {
+		return errno
+	}
+	return n.LoopbackNode.Rmdir(ctx, name)
+}
+
+func (n *faultNode) Unlink(ctx context.Context, name string) syscall.Errno {
+	if errno := n.injector.inject(ctx, OpUnlink); errno != 0 {
+		return errno
+	}
+	return n.LoopbackNode.Unlink(ctx, name)
+}
+
+func (n *faultNode) Rename(ctx context.Context, name string, newParent fs.InodeEmbedder, newName string, flags uint32) syscall.Errno {
+	if errno := n.injector.inject(ctx, OpRename); errno != 0 {
+		return errno
+	}
+	return n.LoopbackNode.Rename(ctx, name, newParent, newName, flags)
+}
+
+// Serve mounts the fault injecting filesystem over dir and serves it until the context is done.
+// The original content of dir is accessed through a file descriptor opened before mounting.
+func Serve(ctx context.Context, dir string, c Config) error {
+	fd, err := unix.Open(dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", dir, err)
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	loopback, err := fs.NewLoopbackRoot(fmt.Sprintf("/proc/self/fd/%d", fd))
+	if err != nil {
+		return fmt.Errorf("failed to create loopback for %s: %w", dir, err)
+	}
+	root := &faultNode{LoopbackNode: loopback.(*fs.LoopbackNode), injector: newInjector(c)}
+
+	server, err := fs.Mount(dir, root, &fs.Options{
+		MountOptions: fuse.MountOptions{
+			AllowOther:  true,
+			DirectMount: true,
+			FsName:      dir,
+			Name:        "steadybit-fsfault",
+			// let the kernel check the permissions, as the filesystem is served by root
+			Options: []string{"default_permissions"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mount %s: %w", dir, err)
+	}
+	log.Info().Str("dir", dir).Interface("config", c).Msg("Injecting filesystem faults")
+
+	<-ctx.Done()
+
+	if err := server.Unmount(); err != nil {
+		log.Warn().Err(err).Str("dir", dir).Msg("Failed to unmount, detaching lazily")
+		if err := unix.Unmount(dir, unix.MNT_DETACH); err != nil {
+			return fmt.Errorf("failed to unmount %s: %w", dir, err)
+		}
+	}
+
+	// after a lazy detach the filesystem is served until the last file is closed, exiting aborts the connection
+	served := make(chan struct{})
+	go func() {
+		server.Wait()
+		close(served)
+	}()
+	select {
+	case <-served:
+	case <-time.After(10 * time.Second):
+		log.Warn().Str("dir", dir).Msg("Filesystem still in use, aborting")
+	}
+	return nil
+}
+
+// Main serves the fault injecting filesystem, it is run by the extension in the host's mount namespace.
+func Main(args []string) int {
+	dir, c, err := parseArgs(args)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid arguments")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := Serve(ctx, dir, c); err != nil {
+		log.Error().Err(err).Msg("Failed to inject filesystem faults")
+		return 1
+	}
+	return 0
+}
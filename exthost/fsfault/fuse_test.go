@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package fsfault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("mounting requires root")
+	}
+	if _, err := os.Stat("/dev/fuse"); err != nil {
+		t.Skip("fuse is not available")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), []byte("hello"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- Serve(ctx, dir, Config{Error: "EIO", Probability: 100, Operations: []string{OpRead, OpCreate}})
+	}()
+	require.Eventually(t, func() bool {
+		var st syscall.Statfs_t
+		return syscall.Statfs(dir, &st) == nil && st.Type == 0x65735546 // FUSE_SUPER_MAGIC
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err := os.ReadFile(filepath.Join(dir, "data"))
+	assert.ErrorIs(t, err, syscall.EIO)
+	err = os.WriteFile(filepath.Join(dir, "other"), []byte("x"), 0644)
+	assert.ErrorIs(t, err, syscall.EIO)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	cancel()
+	require.NoError(t, <-served)
+	content, err := os.ReadFile(filepath.Join(dir, "data"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+}
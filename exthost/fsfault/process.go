@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package fsfault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_commons/ociruntime"
+	"github.com/steadybit/action-kit/go/action_kit_commons/utils"
+	"github.com/steadybit/extension-host/exthost/hostns"
+	"github.com/steadybit/extension-host/exthost/mounts"
+)
+
+var mountTimeout = 10 * time.Second
+
+// Process runs the extension's binary in the host's mount namespace to serve the fault injecting filesystem.
+type Process struct {
+	cmd   *exec.Cmd
+	state *utils.BackgroundState
+	dir   string
+}
+
+func NewProcess(targetProcess ociruntime.LinuxProcessInfo, dir string, c Config) *Process {
+	args := append([]string{
+		"nsenter", "-t", "1", "-C", "--",
+		// leave the cgroup of the extension, its device restrictions deny access to /dev/fuse
+		"cgexec", "-g", fmt.Sprintf("memory:%s", targetProcess.CGroupPath),
+		"nsenter", "-t", "1", "-m", "--",
+		fmt.Sprintf("/proc/%d/exe", os.Getpid()), Subcommand, "--dir", dir,
+	}, c.Args()...)
+
+	return &Process{
+		cmd: utils.RootCommandContext(context.Background(), args[0], args[1:]...),
+		dir: dir,
+	}
+}
+
+// Start starts the process and waits until the filesystem is mounted.
+func (p *Process) Start() error {
+	log.Info().
+		Strs("args", p.cmd.Args).
+		Msg("Starting filesystem fault injection")
+
+	state, err := utils.RunCommandInBackground(p.cmd, log.With().Str("id", "fsfault").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+	p.state = state
+
+	deadline := time.Now().Add(mountTimeout)
+	for time.Now().Before(deadline) {
+		if exited, err := p.state.Exited(); exited {
+			return fmt.Errorf("exited before mounting %s: %w", p.dir, err)
+		}
+		if mounted, err := IsMounted(p.dir); err != nil {
+			_ = p.Stop()
+			return err
+		} else if mounted {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	_ = p.Stop()
+	return fmt.Errorf("%s was not mounted within %s", p.dir, mountTimeout)
+}
+
+func (p *Process) Exited() (bool, error) {
+	return p.state.Exited()
+}
+
+// Stop interrupts the process, which unmounts the filesystem.
+func (p *Process) Stop() error {
+	log.Info().
+		Str("dir", p.dir).
+		Msg("Stopping filesystem fault injection")
+
+	ctx := context.Background()
+	if err := utils.RootCommandContext(ctx, "kill", "-s", "SIGINT", strconv.Itoa(p.cmd.Process.Pid)).Run(); err != nil {
+		log.Warn().Err(err).Msg("failed to send SIGINT to fsfault")
+	}
+
+	timer := time.AfterFunc(20*time.Second, func() {
+		if err := utils.RootCommandContext(ctx, "kill", "-s", "SIGKILL", strconv.Itoa(p.cmd.Process.Pid)).Run(); err != nil {
+			log.Warn().Err(err).Msg("failed to send SIGKILL to fsfault")
+		}
+	})
+	p.state.Wait()
+	timer.Stop()
+
+	return Unmount(ctx, p.dir)
+}
+
+// IsMounted reports whether the fault injecting filesystem is mounted on dir.
+func IsMounted(dir string) (bool, error) {
+	hostMounts, err := mounts.ReadHostMountInfo()
+	if err != nil {
+		return false, err
+	}
+	for _, m := range hostMounts {
+		if m.MountPoint == dir && m.FsType == FsType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Unmount detaches a fault injecting filesystem left behind on dir, e.g. when the process was killed.
+func Unmount(ctx context.Context, dir string) error {
+	mounted, err := IsMounted(dir)
+	if err != nil || !mounted {
+		return err
+	}
+	if _, err := hostns.Run(ctx, "umount", "--lazy", "--", dir); err != nil {
+		return fmt.Errorf("failed to unmount %s, you have to unmount it manually: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package hostfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// RootPath is the root directory of the host as seen from the extension.
+var RootPath = "/proc/1/root"
+
+// Path returns the path to access the given host path from the extension.
+func Path(hostPath string) string {
+	return filepath.Join(RootPath, hostPath)
+}
+
+// maxSymlinks is the number of symbolic links followed before giving up, like the kernel's MAXSYMLINKS.
+const maxSymlinks = 40
+
+// EvalSymlinks returns the host path with all symbolic links resolved. Unlike filepath.EvalSymlinks on Path,
+// absolute link targets are resolved relative to the host's root instead of the extension's root.
+func EvalSymlinks(hostPath string) (string, error) {
+	resolved := "/"
+	rest := strings.Split(hostPath, "/")
+	links := 0
+	for len(rest) > 0 {
+		name := rest[0]
+		rest = rest[1:]
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, name)
+		fi, err := os.Lstat(Path(next))
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("too many levels of symbolic links in %s", hostPath)
+		}
+		target, err := os.Readlink(Path(next))
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			resolved = "/"
+		}
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+	return resolved, nil
+}
+
+// Usage is the space and inode usage of a filesystem.
+type Usage struct {
+	Total      uint64
+	Free       uint64
+	Available  uint64
+	Inodes     uint64
+	InodesFree uint64
+	ReadOnly   bool
+}
+
+func (u Usage) Used() uint64 {
+	return u.Total - u.Free
+}
+
+func (u Usage) UsedPercent() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return float64(u.Used()) * 100 / float64(u.Total)
+}
+
+func (u Usage) InodesUsed() uint64 {
+	return u.Inodes - u.InodesFree
+}
+
+func (u Usage) InodesUsedPercent() float64 {
+	if u.Inodes == 0 {
+		return 0
+	}
+	return float64(u.InodesUsed()) * 100 / float64(u.Inodes)
+}
+
+// Statfs returns the usage of the filesystem containing the given host path.
+func Statfs(hostPath string) (Usage, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(Path(hostPath), &st); err != nil {
+		return Usage{}, fmt.Errorf("failed to stat filesystem of %s: %w", hostPath, err)
+	}
+	bsize := uint64(st.Bsize)
+	return Usage{
+		Total:      st.Blocks * bsize,
+		Free:       st.Bfree * bsize,
+		Available:  st.Bavail * bsize,
+		Inodes:     st.Files,
+		InodesFree: st.Ffree,
+		ReadOnly:   st.Flags&unix.ST_RDONLY != 0,
+	}, nil
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package hostfs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatfs(t *testing.T) {
+	oldRootPath := RootPath
+	RootPath = t.TempDir()
+	t.Cleanup(func() {
+		RootPath = oldRootPath
+	})
+
+	usage, err := Statfs("/")
+	require.NoError(t, err)
+	assert.Greater(t, usage.Total, uint64(0))
+	assert.LessOrEqual(t, usage.Free, usage.Total)
+	assert.GreaterOrEqual(t, usage.UsedPercent(), float64(0))
+
+	_, err = Statfs("/does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestUsage(t *testing.T) {
+	u := Usage{Total: 1000, Free: 250, Inodes: 100, InodesFree: 10}
+	assert.Equal(t, uint64(750), u.Used())
+	assert.Equal(t, float64(75), u.UsedPercent())
+	assert.Equal(t, uint64(90), u.InodesUsed())
+	assert.Equal(t, float64(90), u.InodesUsedPercent())
+	assert.Equal(t, float64(0), Usage{}.InodesUsedPercent())
+}
+
+func TestEvalSymlinks(t *testing.T) {
+	oldRootPath := RootPath
+	RootPath = t.TempDir()
+	t.Cleanup(func() {
+		RootPath = oldRootPath
+	})
+
+	require.NoError(t, os.MkdirAll(Path("/etc/app"), 0755))
+	require.NoError(t, os.WriteFile(Path("/etc/app/app.conf"), []byte("x"), 0644))
+	require.NoError(t, os.Symlink("/etc/app", Path("/etc/current")))
+	require.NoError(t, os.Symlink("app.conf", Path("/etc/app/link.conf")))
+	require.NoError(t, os.Symlink("../current/link.conf", Path("/etc/app/relative.conf")))
+	require.NoError(t, os.Symlink("loop", Path("/etc/loop")))
+
+	tests := map[string]string{
+		"/etc/app/app.conf":              "/etc/app/app.conf",
+		"/etc/current/app.conf":          "/etc/app/app.conf",
+		"/etc/current/link.conf":         "/etc/app/app.conf",
+		"/etc/app/relative.conf":         "/etc/app/app.conf",
+		"/etc/./current/../app/app.conf": "/etc/app/app.conf",
+	}
+	for path, want := range tests {
+		got, err := EvalSymlinks(path)
+		require.NoError(t, err, path)
+		assert.Equal(t, want, got, path)
+	}
+
+	_, err := EvalSymlinks("/etc/loop")
+	assert.ErrorContains(t, err, "too many levels of symbolic links")
+	_, err = EvalSymlinks("/etc/missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
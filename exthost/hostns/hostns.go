@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package hostns
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/steadybit/action-kit/go/action_kit_commons/utils"
+)
+
+var nsenterPath = utils.LocateExecutable("nsenter", "STEADYBIT_EXTENSION_NSENTER_PATH")
+
+// Command returns a command executed as root within the mount, uts, ipc, network, pid and cgroup namespaces of the host's init process.
+func Command(ctx context.Context, name string, arg ...string) *exec.Cmd {
+	args := append([]string{"-t", "1", "-m", "-u", "-i", "-n", "-p", "-C", "--", name}, arg...)
+	return utils.RootCommandContext(ctx, nsenterPath, args...)
+}
+
+// Run executes the command within the host namespaces and returns its trimmed stdout.
+func Run(ctx context.Context, name string, arg ...string) (string, error) {
+	var outb, errb bytes.Buffer
+	cmd := Command(ctx, name, arg...)
+	cmd.Stdout = &outb
+	cmd.Stderr = &errb
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(arg, " "), err, strings.TrimSpace(errb.String()))
+	}
+	return strings.TrimSpace(outb.String()), nil
+}
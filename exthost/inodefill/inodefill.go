@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package inodefill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_commons/diskfill"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"golang.org/x/sys/unix"
+)
+
+const filesPerDir = 10000
+
+var workers = max(runtime.NumCPU(), 4)
+
+type inodeFill struct {
+	dir     string
+	files   uint64
+	created atomic.Uint64
+	cancel  context.CancelFunc
+	done    chan struct{}
+	err     error
+}
+
+// Dir returns the host directory holding the files of the given execution.
+func Dir(path string, executionId uuid.UUID) string {
+	return filepath.Join(path, fmt.Sprintf("steadybit-inode-fill-%s", executionId))
+}
+
+// New returns a diskfill.Diskfill creating empty files in dir until the given percentage of the inodes of the filesystem is used.
+func New(dir string, percentage int) (diskfill.Diskfill, error) {
+	usage, err := hostfs.Statfs(filepath.Dir(dir))
+	if err != nil {
+		return nil, err
+	}
+	if usage.Inodes == 0 {
+		return nil, fmt.Errorf("the filesystem of %s has no fixed number of inodes", filepath.Dir(dir))
+	}
+
+	target := usage.Inodes * uint64(percentage) / 100
+	var files uint64
+	if target > usage.InodesUsed() {
+		files = target - usage.InodesUsed()
+	}
+	return &inodeFill{dir: dir, files: files}, nil
+}
+
+func (f *inodeFill) Start() error {
+	if f.Noop() {
+		return nil
+	}
+
+	log.Info().
+		Str("dir", f.dir).
+		Uint64("files", f.files).
+		Msg("Starting inode fill")
+
+	if err := os.Mkdir(hostfs.Path(f.dir), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", f.dir, err)
+	}
+	f.created.Store(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	f.done = make(chan struct{})
+	go func() {
+		defer close(f.done)
+		f.err = f.fill(ctx)
+	}()
+	return nil
+}
+
+func (f *inodeFill) fill(ctx context.Context) error {
+	// every directory consumes an inode as well
+	dirs := (f.files + filesPerDir) / (filesPerDir + 1)
+	next := atomic.Uint64{}
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				d := next.Add(1) - 1
+				if d >= dirs {
+					return
+				}
+				if err := f.fillDir(ctx, d); err != nil {
+					errs[w] = err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if errors.Is(err, unix.ENOSPC) {
+		log.Info().Str("dir", f.dir).Uint64("created", f.created.Load()).Msg("No inodes left on filesystem")
+		return nil
+	}
+	return err
+}
+
+func (f *inodeFill) fillDir(ctx context.Context, d uint64) error {
+	dir := filepath.Join(hostfs.Path(f.dir), strconv.FormatUint(d, 10))
+	if err := unix.Mkdir(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	f.created.Add(1)
+
+	for i := 0; i < filesPerDir && ctx.Err() == nil; i++ {
+		if f.created.Load() >= f.files {
+			return nil
+		}
+		fd, err := unix.Open(filepath.Join(dir, strconv.Itoa(i)), unix.O_CREAT|unix.O_EXCL|unix.O_WRONLY|unix.O_CLOEXEC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create file in %s: %w", dir, err)
+		}
+		_ = unix.Close(fd)
+		f.created.Add(1)
+	}
+	return nil
+}
+
+func (f *inodeFill) Exited() (bool, error) {
+	if f.done == nil {
+		return true, nil
+	}
+	select {
+	case <-f.done:
+		return true, f.err
+	default:
+		return false, nil
+	}
+}
+
+func (f *inodeFill) Stop() error {
+	if f.done == nil {
+		return nil
+	}
+	log.Info().
+		Str("dir", f.dir).
+		Msg("Stopping inode fill")
+
+	f.cancel()
+	<-f.done
+	return Remove(f.dir)
+}
+
+func (f *inodeFill) Args() []string {
+	return []string{"create", strconv.FormatUint(f.files, 10), "files in", f.dir}
+}
+
+func (f *inodeFill) Noop() bool {
+	return f.files == 0
+}
+
+// Created returns the number of inodes created so far.
+func (f *inodeFill) Created() uint64 {
+	return f.created.Load()
+}
+
+// Remove deletes the directory created by the inode fill, removing the subdirectories in parallel.
+func Remove(dir string) error {
+	root := hostfs.Path(dir)
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	subdirs := make(chan string)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for subdir := range subdirs {
+				if err := os.RemoveAll(subdir); err != nil {
+					errs[w] = errors.Join(errs[w], err)
+				}
+			}
+		}(w)
+	}
+	for _, entry := range entries {
+		subdirs <- filepath.Join(root, entry.Name())
+	}
+	close(subdirs)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to remove %s, you have to remove it manually: %w", dir, err)
+	}
+	if err := os.Remove(root); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s, you have to remove it manually: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package inodefill
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeHostRoot(t *testing.T) {
+	oldRootPath := hostfs.RootPath
+	hostfs.RootPath = t.TempDir()
+	t.Cleanup(func() {
+		hostfs.RootPath = oldRootPath
+	})
+}
+
+func TestInodeFill(t *testing.T) {
+	fakeHostRoot(t)
+	dir := Dir("/", uuid.New())
+
+	fill := &inodeFill{dir: dir, files: 25}
+	require.NoError(t, fill.Start())
+	assert.Eventually(t, func() bool {
+		exited, err := fill.Exited()
+		return exited && err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(25), fill.Created())
+
+	entries, err := os.ReadDir(filepath.Join(hostfs.Path(dir), "0"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 23)
+
+	require.NoError(t, fill.Stop())
+	_, err = os.Stat(hostfs.Path(dir))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNew_noop(t *testing.T) {
+	fakeHostRoot(t)
+
+	fill, err := New(Dir("/", uuid.New()), 0)
+	require.NoError(t, err)
+	assert.True(t, fill.Noop())
+	require.NoError(t, fill.Start())
+	exited, err := fill.Exited()
+	assert.True(t, exited)
+	assert.NoError(t, err)
+	assert.NoError(t, fill.Stop())
+}
+
+func TestRemove(t *testing.T) {
+	fakeHostRoot(t)
+	dir := Dir("/", uuid.New())
+	for _, sub := range []string{"0", "1", "2"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(hostfs.Path(dir), sub), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(hostfs.Path(dir), sub, "f"), nil, 0600))
+	}
+
+	require.NoError(t, Remove(dir))
+	_, err := os.Stat(hostfs.Path(dir))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, Remove(dir))
+}
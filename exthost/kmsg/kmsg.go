@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package kmsg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+var kmsgPath = "/dev/kmsg"
+
+// Record is a single message of the kernel log.
+type Record struct {
+	Seq      uint64
+	Priority int
+	Message  string
+}
+
+// OomKill is a process killed by the OOM killer.
+type OomKill struct {
+	Pid    int
+	Name   string
+	Cgroup string
+}
+
+func (o OomKill) String() string {
+	if o.Cgroup == "" {
+		return fmt.Sprintf("%s (pid %d)", o.Name, o.Pid)
+	}
+	return fmt.Sprintf("%s (pid %d, cgroup %s)", o.Name, o.Pid, o.Cgroup)
+}
+
+// Read returns all records currently held in the kernel log buffer.
+func Read() ([]Record, error) {
+	fd, err := unix.Open(kmsgPath, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", kmsgPath, err)
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	var data []byte
+	buf := make([]byte, 8192)
+	for {
+		n, err := unix.Read(fd, buf)
+		if errors.Is(err, unix.EAGAIN) {
+			break
+		}
+		if errors.Is(err, unix.EPIPE) || errors.Is(err, unix.EINTR) {
+			// EPIPE: the record was overwritten while reading, continue with the next one
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", kmsgPath, err)
+		}
+		if n == 0 {
+			break
+		}
+		data = append(data, buf[:n]...)
+	}
+	return parseRecords(string(data)), nil
+}
+
+// LastSeq returns the sequence number of the latest record in the kernel log buffer.
+func LastSeq() (uint64, error) {
+	records, err := Read()
+	if err != nil {
+		return 0, err
+	}
+	var seq uint64
+	for _, r := range records {
+		seq = max(seq, r.Seq)
+	}
+	return seq, nil
+}
+
+// parseRecords parses records in the format "priority,seq,timestamp,flags;message".
+// Continuation lines holding key/value pairs start with a space and are ignored.
+func parseRecords(data string) []Record {
+	var records []Record
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" || strings.HasPrefix(line, " ") {
+			continue
+		}
+		prefix, message, found := strings.Cut(line, ";")
+		if !found {
+			continue
+		}
+		fields := strings.Split(prefix, ",")
+		if len(fields) < 2 {
+			continue
+		}
+		priority, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		seq, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		records = append(records, Record{Seq: seq, Priority: priority & 7, Message: message})
+	}
+	return records
+}
+
+// OomKills returns the processes killed by the OOM killer in the given records.
+func OomKills(records []Record) []OomKill {
+	var kills []OomKill
+	index := make(map[int]int)
+	for _, r := range records {
+		kill, ok := parseOomKill(r.Message)
+		if !ok {
+			continue
+		}
+		if i, seen := index[kill.Pid]; seen {
+			if kills[i].Cgroup == "" {
+				kills[i].Cgroup = kill.Cgroup
+			}
+			continue
+		}
+		index[kill.Pid] = len(kills)
+		kills = append(kills, kill)
+	}
+	return kills
+}
+
+// parseOomKill parses the "oom-kill:" summary line and the "Killed process" line of older kernels.
+func parseOomKill(message string) (OomKill, bool) {
+	if rest, found := strings.CutPrefix(message, "oom-kill:"); found {
+		var kill OomKill
+		for _, field := range strings.Split(rest, ",") {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "task_memcg":
+				kill.Cgroup = value
+			case "task":
+				kill.Name = value
+			case "pid":
+				kill.Pid, _ = strconv.Atoi(value)
+			}
+		}
+		return kill, kill.Pid > 0
+	}
+
+	_, rest, found := strings.Cut(message, "Killed process ")
+	if !found {
+		return OomKill{}, false
+	}
+	pid, rest, _ := strings.Cut(rest, " ")
+	var kill OomKill
+	kill.Pid, _ = strconv.Atoi(pid)
+	if start, end := strings.Index(rest, "("), strings.Index(rest, ")"); start >= 0 && end > start {
+		kill.Name = rest[start+1 : end]
+	}
+	return kill, kill.Pid > 0
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package kmsg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleKmsg = `6,1200,5000000,-;systemd[1]: Started nginx.service.
+4,1201,5100000,-;stress invoked oom-killer: gfp_mask=0x140cca(GFP_HIGHUSER_MOVABLE|__GFP_COMP), order=0, oom_score_adj=0
+ SUBSYSTEM=memory
+6,1202,5100001,-;oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/system.slice/nginx.service,task=nginx,pid=4242,uid=33
+3,1203,5100002,-;Out of memory: Killed process 4242 (nginx) total-vm:1000kB, anon-rss:500kB, file-rss:0kB, shmem-rss:0kB, UID:33 pgtables:40kB oom_score_adj:0
+3,1204,5200000,-;Memory cgroup out of memory: Killed process 777 (java) total-vm:1000kB, anon-rss:500kB, file-rss:0kB, shmem-rss:0kB, UID:0 pgtables:40kB oom_score_adj:0
+`
+
+func Test_parseRecords(t *testing.T) {
+	records := parseRecords(sampleKmsg)
+	require.Len(t, records, 5)
+	assert.Equal(t, Record{Seq: 1200, Priority: 6, Message: "systemd[1]: Started nginx.service."}, records[0])
+	assert.Equal(t, uint64(1204), records[4].Seq)
+}
+
+func TestOomKills(t *testing.T) {
+	kills := OomKills(parseRecords(sampleKmsg))
+	assert.Equal(t, []OomKill{
+		{Pid: 4242, Name: "nginx", Cgroup: "/system.slice/nginx.service"},
+		{Pid: 777, Name: "java"},
+	}, kills)
+	assert.Equal(t, "nginx (pid 4242, cgroup /system.slice/nginx.service)", kills[0].String())
+	assert.Equal(t, "java (pid 777)", kills[1].String())
+}
+
+func TestRead(t *testing.T) {
+	oldPath := kmsgPath
+	kmsgPath = filepath.Join(t.TempDir(), "kmsg")
+	t.Cleanup(func() {
+		kmsgPath = oldPath
+	})
+	require.NoError(t, os.WriteFile(kmsgPath, []byte(sampleKmsg), 0644))
+
+	records, err := Read()
+	require.NoError(t, err)
+	assert.Len(t, records, 5)
+
+	seq, err := LastSeq()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1204), seq)
+}
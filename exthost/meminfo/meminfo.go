@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package meminfo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var procBasePath = "/proc"
+
+// Meminfo holds the values of /proc/meminfo in bytes.
+type Meminfo struct {
+	MemTotal     uint64
+	MemFree      uint64
+	MemAvailable uint64
+	Cached       uint64
+	AnonPages    uint64
+	SwapTotal    uint64
+	SwapFree     uint64
+}
+
+// Used returns the memory in use, not counting reclaimable memory.
+func (m Meminfo) Used() uint64 {
+	if m.MemAvailable > m.MemTotal {
+		return 0
+	}
+	return m.MemTotal - m.MemAvailable
+}
+
+func (m Meminfo) SwapUsed() uint64 {
+	if m.SwapFree > m.SwapTotal {
+		return 0
+	}
+	return m.SwapTotal - m.SwapFree
+}
+
+func Read() (Meminfo, error) {
+	f, err := os.Open(filepath.Join(procBasePath, "meminfo"))
+	if err != nil {
+		return Meminfo{}, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return parseMeminfo(f)
+}
+
+func parseMeminfo(r io.Reader) (Meminfo, error) {
+	values, err := parseValues(r)
+	if err != nil {
+		return Meminfo{}, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+	if _, ok := values["MemTotal"]; !ok {
+		return Meminfo{}, fmt.Errorf("meminfo is missing MemTotal")
+	}
+
+	return Meminfo{
+		MemTotal:     values["MemTotal"],
+		MemFree:      values["MemFree"],
+		MemAvailable: values["MemAvailable"],
+		Cached:       values["Cached"],
+		AnonPages:    values["AnonPages"],
+		SwapTotal:    values["SwapTotal"],
+		SwapFree:     values["SwapFree"],
+	}, nil
+}
+
+// ProcessUsage returns the resident and swapped out memory of the process in bytes.
+func ProcessUsage(pid int) (uint64, error) {
+	f, err := os.Open(filepath.Join(procBasePath, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read status of process %d: %w", pid, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	values, err := parseValues(f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read status of process %d: %w", pid, err)
+	}
+	return values["VmRSS"] + values["VmSwap"], nil
+}
+
+// parseValues parses lines in the format "Key: value [kB]" as used by /proc/meminfo and /proc/<pid>/status.
+// Lines without a numeric value are skipped.
+func parseValues(r io.Reader) (map[string]uint64, error) {
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			v *= 1024
+		}
+		values[key] = v
+	}
+	return values, scanner.Err()
+}
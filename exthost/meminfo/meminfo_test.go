@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package meminfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleMeminfo = `MemTotal:       16283652 kB
+MemFree:         1234567 kB
+MemAvailable:    8141826 kB
+Buffers:          123456 kB
+Cached:          5000000 kB
+SwapCached:            0 kB
+AnonPages:       4000000 kB
+SwapTotal:       2097148 kB
+SwapFree:        1048574 kB
+HugePages_Total:       0
+`
+
+func Test_parseMeminfo(t *testing.T) {
+	m, err := parseMeminfo(strings.NewReader(sampleMeminfo))
+	require.NoError(t, err)
+	assert.Equal(t, Meminfo{
+		MemTotal:     16283652 * 1024,
+		MemFree:      1234567 * 1024,
+		MemAvailable: 8141826 * 1024,
+		Cached:       5000000 * 1024,
+		AnonPages:    4000000 * 1024,
+		SwapTotal:    2097148 * 1024,
+		SwapFree:     1048574 * 1024,
+	}, m)
+	assert.Equal(t, uint64(8141826*1024), m.Used())
+	assert.Equal(t, uint64(1048574*1024), m.SwapUsed())
+
+	_, err = parseMeminfo(strings.NewReader("MemFree: 1 kB\n"))
+	assert.Error(t, err)
+}
+
+func TestRead(t *testing.T) {
+	oldBasePath := procBasePath
+	procBasePath = t.TempDir()
+	t.Cleanup(func() {
+		procBasePath = oldBasePath
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(procBasePath, "meminfo"), []byte(sampleMeminfo), 0644))
+
+	m, err := Read()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(16283652*1024), m.MemTotal)
+}
+
+func TestProcessUsage(t *testing.T) {
+	oldBasePath := procBasePath
+	procBasePath = t.TempDir()
+	t.Cleanup(func() {
+		procBasePath = oldBasePath
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(procBasePath, "42"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procBasePath, "42", "status"), []byte("Name:\tmemfill\nState:\tS (sleeping)\nPid:\t42\nVmRSS:\t  2048 kB\nVmSwap:\t  1024 kB\n"), 0644))
+
+	usage, err := ProcessUsage(42)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3072*1024), usage)
+
+	_, err = ProcessUsage(43)
+	assert.Error(t, err)
+}
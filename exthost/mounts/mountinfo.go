@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package mounts
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var procBasePath = "/proc"
+
+type MountInfo struct {
+	MountId      int
+	ParentId     int
+	Major        uint32
+	Minor        uint32
+	Root         string
+	MountPoint   string
+	Options      []string
+	FsType       string
+	Source       string
+	SuperOptions []string
+}
+
+// Device returns the device of the mount in the major:minor notation.
+func (m MountInfo) Device() string {
+	return fmt.Sprintf("%d:%d", m.Major, m.Minor)
+}
+
+// ReadOnly reports whether the mount is mounted read-only.
+func (m MountInfo) ReadOnly() bool {
+	for _, o := range m.Options {
+		if o == "ro" {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryBacked tells whether the filesystem stores its files in memory instead of on a disk.
+func (m MountInfo) MemoryBacked() bool {
+	return m.FsType == "tmpfs" || m.FsType == "ramfs"
+}
+
+// ReadMountInfo returns the mounts as seen by the given process.
+func ReadMountInfo(pid int) ([]MountInfo, error) {
+	f, err := os.Open(filepath.Join(procBasePath, strconv.Itoa(pid), "mountinfo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mountinfo: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return parseMountInfo(f)
+}
+
+// ReadHostMountInfo returns the mounts as seen by the host's init process.
+func ReadHostMountInfo() ([]MountInfo, error) {
+	return ReadMountInfo(1)
+}
+
+// FindMount returns the mount containing the given absolute path. When mounts are stacked on the same mount point, the last one wins.
+func FindMount(mounts []MountInfo, path string) (*MountInfo, error) {
+	if !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path %s is not absolute", path)
+	}
+	path = filepath.Clean(path)
+
+	var result *MountInfo
+	for i, m := range mounts {
+		if !isPathBelow(path, m.MountPoint) {
+			continue
+		}
+		if result == nil || len(m.MountPoint) >= len(result.MountPoint) {
+			result = &mounts[i]
+		}
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("no mount found for %s", path)
+	}
+	return result, nil
+}
+
+// containerPaths are managed by container runtimes and kubelet, their mounts are volumes of containers.
+var containerPaths = []string{"/proc", "/sys", "/dev", "/run", "/var/run", "/var/lib/docker", "/var/lib/containerd", "/var/lib/kubelet"}
+
+// DiskMountPoints returns the sorted mount points of the writable disk filesystems, omitting bind mounts of
+// subdirectories and the mounts of containers.
+func DiskMountPoints(mounts []MountInfo) []string {
+	seen := make(map[string]bool)
+	for _, m := range mounts {
+		if m.Root != "/" || m.ReadOnly() || seen[m.MountPoint] {
+			continue
+		}
+		if !strings.HasPrefix(m.Source, "/dev/") && m.FsType != "zfs" {
+			continue
+		}
+		if slices.ContainsFunc(containerPaths, func(p string) bool { return isPathBelow(m.MountPoint, p) }) {
+			continue
+		}
+		seen[m.MountPoint] = true
+	}
+	result := make([]string, 0, len(seen))
+	for mountPoint := range seen {
+		result = append(result, mountPoint)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func isPathBelow(path, mountPoint string) bool {
+	if mountPoint == "/" || path == mountPoint {
+		return true
+	}
+	return strings.HasPrefix(path, mountPoint+"/")
+}
+
+func parseMountInfo(r io.Reader) ([]MountInfo, error) {
+	var result []MountInfo
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		m, err := parseMountInfoLine(line)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mountinfo: %w", err)
+	}
+	return result, nil
+}
+
+func parseMountInfoLine(line string) (MountInfo, error) {
+	fields := strings.Fields(line)
+	separator := -1
+	for i, f := range fields {
+		if f == "-" {
+			separator = i
+			break
+		}
+	}
+	if separator < 6 || len(fields) < separator+3 {
+		return MountInfo{}, fmt.Errorf("invalid mountinfo line: %s", line)
+	}
+
+	mountId, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("invalid mount id in mountinfo line: %s", line)
+	}
+	parentId, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("invalid parent id in mountinfo line: %s", line)
+	}
+	major, minor, found := strings.Cut(fields[2], ":")
+	if !found {
+		return MountInfo{}, fmt.Errorf("invalid device in mountinfo line: %s", line)
+	}
+	majorNum, err := strconv.ParseUint(major, 10, 32)
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("invalid device in mountinfo line: %s", line)
+	}
+	minorNum, err := strconv.ParseUint(minor, 10, 32)
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("invalid device in mountinfo line: %s", line)
+	}
+
+	m := MountInfo{
+		MountId:    mountId,
+		ParentId:   parentId,
+		Major:      uint32(majorNum),
+		Minor:      uint32(minorNum),
+		Root:       unescape(fields[3]),
+		MountPoint: unescape(fields[4]),
+		Options:    strings.Split(fields[5], ","),
+		FsType:     fields[separator+1],
+		Source:     unescape(fields[separator+2]),
+	}
+	if len(fields) > separator+3 {
+		m.SuperOptions = strings.Split(fields[separator+3], ",")
+	}
+	return m, nil
+}
+
+// unescape decodes the octal escapes (e.g. \040 for a space) used by the kernel in mountinfo.
+func unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
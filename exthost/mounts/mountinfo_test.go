@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package mounts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleMountInfo = `22 1 253:0 / / rw,relatime shared:1 - ext4 /dev/mapper/vg-root rw,errors=remount-ro
+23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+24 22 0:22 / /tmp rw,nosuid,nodev shared:5 - tmpfs tmpfs rw,size=8192k
+25 22 259:2 / /var/lib/data rw,noatime shared:30 - xfs /dev/nvme0n1p2 rw,attr2
+26 25 259:3 / /var/lib/data/with\040space ro,noatime shared:31 - xfs /dev/nvme0n1p3 rw
+`
+
+func Test_parseMountInfo(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(sampleMountInfo))
+	require.NoError(t, err)
+	require.Len(t, mounts, 5)
+
+	assert.Equal(t, MountInfo{
+		MountId:      22,
+		ParentId:     1,
+		Major:        253,
+		Minor:        0,
+		Root:         "/",
+		MountPoint:   "/",
+		Options:      []string{"rw", "relatime"},
+		FsType:       "ext4",
+		Source:       "/dev/mapper/vg-root",
+		SuperOptions: []string{"rw", "errors=remount-ro"},
+	}, mounts[0])
+	assert.Equal(t, "/var/lib/data/with space", mounts[4].MountPoint)
+	assert.True(t, mounts[4].ReadOnly())
+	assert.False(t, mounts[3].ReadOnly())
+	assert.Equal(t, "259:2", mounts[3].Device())
+}
+
+func Test_parseMountInfo_invalid(t *testing.T) {
+	_, err := parseMountInfo(strings.NewReader("22 1 253:0 / /\n"))
+	assert.Error(t, err)
+}
+
+func TestFindMount(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(sampleMountInfo))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path       string
+		mountPoint string
+		wantErr    bool
+	}{
+		{path: "/", mountPoint: "/"},
+		{path: "/etc/hosts", mountPoint: "/"},
+		{path: "/tmp", mountPoint: "/tmp"},
+		{path: "/tmpdir", mountPoint: "/"},
+		{path: "/var/lib/data/../data/file", mountPoint: "/var/lib/data"},
+		{path: "/var/lib/data/with space/file", mountPoint: "/var/lib/data/with space"},
+		{path: "relative", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			m, err := FindMount(mounts, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.mountPoint, m.MountPoint)
+		})
+	}
+}
+
+func TestDiskMountPoints(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(sampleMountInfo + `27 22 259:2 /nested /srv rw,noatime shared:32 - xfs /dev/nvme0n1p2 rw
+28 22 259:4 / /var/lib/kubelet/pods/abc/volumes/data rw shared:33 - ext4 /dev/nvme1n1 rw
+29 22 0:45 / /tank rw shared:34 - zfs tank rw
+30 22 259:5 / /boot rw shared:35 - vfat /dev/nvme0n1p1 rw
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/", "/boot", "/tank", "/var/lib/data"}, DiskMountPoints(mounts))
+}
+
+func TestMemoryBacked(t *testing.T) {
+	assert.True(t, MountInfo{FsType: "tmpfs"}.MemoryBacked())
+	assert.True(t, MountInfo{FsType: "ramfs"}.MemoryBacked())
+	assert.False(t, MountInfo{FsType: "overlay"}.MemoryBacked())
+}
+
+func TestReadMountInfo(t *testing.T) {
+	oldBasePath := procBasePath
+	procBasePath = t.TempDir()
+	t.Cleanup(func() {
+		procBasePath = oldBasePath
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(procBasePath, "1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procBasePath, "1", "mountinfo"), []byte(sampleMountInfo), 0644))
+
+	mounts, err := ReadHostMountInfo()
+	require.NoError(t, err)
+	assert.Len(t, mounts, 5)
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package pagecache
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_commons/memfill"
+	"github.com/steadybit/action-kit/go/action_kit_commons/ociruntime"
+	"github.com/steadybit/action-kit/go/action_kit_commons/utils"
+	"github.com/steadybit/extension-host/exthost/hostns"
+)
+
+const megabyte = 1024 * 1024
+
+// Opts describes the file used to fill the page cache. File is a path on the host.
+type Opts struct {
+	File     string
+	Size     uint64
+	Duration time.Duration
+}
+
+// script writes the file and keeps reading it so that its pages stay hot in the page cache.
+// The file is removed when the duration is over or the script is interrupted.
+func (o Opts) script() string {
+	return fmt.Sprintf(`f=%s
+trap 'rm -f "$f"; exit 0' INT TERM
+dd if=/dev/zero of="$f" bs=1M count=%d status=none || { rm -f "$f"; exit 1; }
+end=$(( $(date +%%s) + %d ))
+while [ "$(date +%%s)" -lt "$end" ]; do
+  cat "$f" > /dev/null
+  sleep 1 & wait $!
+done
+rm -f "$f"`, shellQuote(o.File), o.megabytes(), int64(o.Duration.Seconds()))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func (o Opts) megabytes() uint64 {
+	return (o.Size + megabyte - 1) / megabyte
+}
+
+func (o Opts) processArgs() []string {
+	return []string{"sh", "-c", o.script()}
+}
+
+type pageCacheFill struct {
+	cmd   *exec.Cmd
+	state *utils.BackgroundState
+	opts  Opts
+}
+
+// New returns a memfill.Memfill filling the page cache with a file in the memory cgroup of the target process.
+func New(targetProcess ociruntime.LinuxProcessInfo, opts Opts) memfill.Memfill {
+	args := append([]string{
+		"nsenter", "-t", "1", "-C", "--",
+		"cgexec", "-g", fmt.Sprintf("memory:%s", targetProcess.CGroupPath),
+		"nsenter", "-t", "1", "-m", "--",
+	}, opts.processArgs()...)
+
+	cmd := utils.RootCommandContext(context.Background(), args[0], args[1:]...)
+	return &pageCacheFill{cmd: cmd, opts: opts}
+}
+
+func (pf *pageCacheFill) Exited() (bool, error) {
+	if pf.state == nil {
+		return true, nil
+	}
+	return pf.state.Exited()
+}
+
+func (pf *pageCacheFill) Start() error {
+	log.Info().
+		Str("file", pf.opts.File).
+		Uint64("size", pf.opts.Size).
+		Msg("Starting page cache fill")
+
+	state, err := utils.RunCommandInBackground(pf.cmd, log.With().Str("id", "pagecache").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+	pf.state = state
+	return nil
+}
+
+func (pf *pageCacheFill) Stop() error {
+	log.Info().
+		Str("file", pf.opts.File).
+		Msg("Stopping page cache fill")
+
+	ctx := context.Background()
+	if pf.cmd == nil || pf.cmd.Process == nil || pf.state == nil {
+		// the fill was never started, only a file left by a failed start needs to be removed
+		return Cleanup(ctx, pf.opts.File)
+	}
+	if err := utils.RootCommandContext(ctx, "kill", "-s", "SIGINT", strconv.Itoa(pf.cmd.Process.Pid)).Run(); err != nil {
+		log.Warn().Err(err).Msg("failed to send SIGINT to page cache fill")
+	}
+
+	timer := time.AfterFunc(10*time.Second, func() {
+		if err := utils.RootCommandContext(ctx, "kill", "-s", "SIGKILL", strconv.Itoa(pf.cmd.Process.Pid)).Run(); err != nil {
+			log.Warn().Err(err).Msg("failed to send SIGKILL to page cache fill")
+		}
+	})
+	pf.state.Wait()
+	timer.Stop()
+
+	// the script removes the file itself, unless it was killed
+	return Cleanup(ctx, pf.opts.File)
+}
+
+func (pf *pageCacheFill) Args() []string {
+	return []string{"fill page cache with", pf.opts.File, fmt.Sprintf("%dMiB", pf.opts.megabytes())}
+}
+
+// Cleanup removes the file used to fill the page cache, dropping its pages from the cache.
+func Cleanup(ctx context.Context, file string) error {
+	if _, err := hostns.Run(ctx, "rm", "-f", "--", file); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package pagecache
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpts_script(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "page $cache's")
+	opts := Opts{File: file, Size: 3*megabyte + 1, Duration: time.Second}
+	assert.Equal(t, uint64(4), opts.megabytes())
+
+	out, err := exec.Command("sh", "-c", opts.script()).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err), "file should be removed after the duration")
+}
+
+func TestPageCacheFill_StopWithoutStart(t *testing.T) {
+	pf := &pageCacheFill{opts: Opts{File: filepath.Join(t.TempDir(), "never-started")}}
+	exited, err := pf.Exited()
+	assert.NoError(t, err)
+	assert.True(t, exited)
+	assert.NotPanics(t, func() { _ = pf.Stop() })
+}
@@ -10,57 +10,77 @@ import (
 	"github.com/mitchellh/go-ps"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_commons/utils"
+	"github.com/steadybit/extension-host/exthost/cgroup"
+	"github.com/steadybit/extension-host/exthost/hostfs"
 	"github.com/steadybit/extension-kit/extutil"
+	"golang.org/x/sys/unix"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 )
 
 func StopProcesses(pid []int, force bool) error {
+	if force {
+		return SignalProcesses(pid, syscall.SIGKILL)
+	}
+	return SignalProcesses(pid, syscall.SIGTERM)
+}
+
+// SignalProcesses sends the signal to the processes, skipping processes which already exited
+func SignalProcesses(pid []int, sig syscall.Signal) error {
 	if len(pid) == 0 {
 		return nil
 	}
 
 	var errs error
 	for _, p := range pid {
-		if process, err := ps.FindProcess(p); err == nil {
-			log.Info().Int("pid", p).Str("name", process.Executable()).Msg("Stopping process")
+		if process, err := ps.FindProcess(p); err == nil && process != nil {
+			log.Info().Int("pid", p).Str("name", process.Executable()).Str("signal", unix.SignalName(sig)).Msg("Signaling process")
 		} else {
 			continue
 		}
 
-		if err := stopProcessUnix(p, force); err != nil {
+		if err := signalProcessUnix(p, sig); err != nil {
 			errs = errors.Join(errs, err)
 		}
 	}
 
 	if errs != nil {
-		return fmt.Errorf("fail to stop processes : %w", errs)
+		return fmt.Errorf("fail to signal processes : %w", errs)
 	}
 	return nil
 }
 
-func stopProcessUnix(pid int, force bool) error {
-	if force {
-		err := syscall.Kill(pid, syscall.SIGKILL)
-		if err != nil {
-			log.Debug().Err(err).Int("pid", pid).Msg("Failed to send SIGKILL via syscall")
-			err = utils.RootCommandContext(context.Background(), "kill", "-9", fmt.Sprintf("%d", pid)).Run()
-		}
-		if err != nil {
-			return fmt.Errorf("failed to send SIGKILL process via exec: %w", err)
-		}
-		return err
-	}
-
-	err := syscall.Kill(pid, syscall.SIGTERM)
+func signalProcessUnix(pid int, sig syscall.Signal) error {
+	err := syscall.Kill(pid, sig)
 	if err != nil {
-		log.Error().Err(err).Int("pid", pid).Msg("failed to send SIGTERM via syscall")
-		err = utils.RootCommandContext(context.Background(), "kill", fmt.Sprintf("%d", pid)).Run()
+		log.Debug().Err(err).Int("pid", pid).Str("signal", unix.SignalName(sig)).Msg("Failed to send signal via syscall")
+		err = utils.RootCommandContext(context.Background(), "kill", "-s", strings.TrimPrefix(unix.SignalName(sig), "SIG"), fmt.Sprintf("%d", pid)).Run()
 	}
 	if err != nil {
-		return fmt.Errorf("failed to send SIGTERM via exec: %w", err)
+		return fmt.Errorf("failed to send %s via exec: %w", unix.SignalName(sig), err)
 	}
-	return err
+	return nil
+}
+
+// ParseSignal parses a signal name like SIGHUP or HUP, or a signal number
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if n, err := strconv.Atoi(name); err == nil {
+		if unix.SignalName(syscall.Signal(n)) == "" {
+			return 0, fmt.Errorf("unknown signal %d", n)
+		}
+		return syscall.Signal(n), nil
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if sig := unix.SignalNum(name); sig != 0 {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unknown signal %s", name)
 }
 
 func FindProcessIds(processOrPid string) []int {
@@ -82,3 +102,169 @@ func FindProcessIds(processOrPid string) []int {
 	}
 	return pids
 }
+
+// Filter selects processes. All set criteria must match.
+type Filter struct {
+	// Process is a PID or a part of the executable name
+	Process string
+	// Cmdline is a regular expression matched against the full command line
+	Cmdline string
+	// User is the name or uid of the process owner
+	User      string
+	ParentPid int
+	// Cgroup is the path of a cgroup, e.g. of a systemd service. Processes of its descendants match too.
+	Cgroup string
+}
+
+func (f Filter) IsEmpty() bool {
+	return f.Process == "" && f.Cmdline == "" && f.User == "" && f.ParentPid == 0 && f.Cgroup == ""
+}
+
+func (f Filter) String() string {
+	var s []string
+	if f.Process != "" {
+		s = append(s, f.Process)
+	}
+	if f.Cmdline != "" {
+		s = append(s, fmt.Sprintf("cmdline=~%s", f.Cmdline))
+	}
+	if f.User != "" {
+		s = append(s, fmt.Sprintf("user=%s", f.User))
+	}
+	if f.ParentPid != 0 {
+		s = append(s, fmt.Sprintf("ppid=%d", f.ParentPid))
+	}
+	if f.Cgroup != "" {
+		s = append(s, fmt.Sprintf("cgroup=%s", f.Cgroup))
+	}
+	return strings.Join(s, ", ")
+}
+
+// Validate checks the regular expression and resolves the user
+func (f Filter) Validate() error {
+	if f.IsEmpty() {
+		return errors.New("no process filter given")
+	}
+	if _, err := regexp.Compile(f.Cmdline); err != nil {
+		return fmt.Errorf("invalid command line pattern: %w", err)
+	}
+	if f.User != "" {
+		if _, err := lookupUid(f.User); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var readProcFile = func(pid int, name string) (string, error) {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/%s", pid, name))
+	return string(b), err
+}
+
+var listProcesses = ps.Processes
+
+var listCgroupPids = func(path string) ([]int, error) {
+	// the systemd hierarchy is used for cgroup v1, the path is the same for all controllers of a service
+	return cgroup.Pids(cgroup.FromPath(path, "systemd"))
+}
+
+// FindProcesses returns the PIDs of the processes matching the filter, excluding the extension itself
+func FindProcesses(f Filter) ([]int, error) {
+	if f.IsEmpty() {
+		return nil, errors.New("no process filter given")
+	}
+	var cmdline *regexp.Regexp
+	if f.Cmdline != "" {
+		var err error
+		if cmdline, err = regexp.Compile(f.Cmdline); err != nil {
+			return nil, fmt.Errorf("invalid command line pattern: %w", err)
+		}
+	}
+	uid := -1
+	if f.User != "" {
+		var err error
+		if uid, err = lookupUid(f.User); err != nil {
+			return nil, err
+		}
+	}
+	pid := extutil.ToInt(f.Process)
+	var inCgroup map[int]bool
+	if f.Cgroup != "" {
+		members, err := listCgroupPids(f.Cgroup)
+		if err != nil {
+			return nil, err
+		}
+		inCgroup = make(map[int]bool, len(members))
+		for _, member := range members {
+			inCgroup[member] = true
+		}
+	}
+
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var pids []int
+	for _, process := range processes {
+		if process.Pid() == os.Getpid() {
+			continue
+		}
+		if pid > 0 && process.Pid() != pid {
+			continue
+		}
+		if pid <= 0 && f.Process != "" && !strings.Contains(strings.TrimSpace(process.Executable()), f.Process) {
+			continue
+		}
+		if f.ParentPid != 0 && process.PPid() != f.ParentPid {
+			continue
+		}
+		if inCgroup != nil && !inCgroup[process.Pid()] {
+			continue
+		}
+		if cmdline != nil {
+			content, err := readProcFile(process.Pid(), "cmdline")
+			if err != nil || !cmdline.MatchString(strings.TrimSpace(strings.ReplaceAll(content, "\x00", " "))) {
+				continue
+			}
+		}
+		if uid >= 0 {
+			status, err := readProcFile(process.Pid(), "status")
+			if err != nil || processUid(status) != uid {
+				continue
+			}
+		}
+		pids = append(pids, process.Pid())
+	}
+	return pids, nil
+}
+
+// processUid returns the real uid from the contents of /proc/<pid>/status
+func processUid(status string) int {
+	for _, line := range strings.Split(status, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "Uid:" {
+			if uid, err := strconv.Atoi(fields[1]); err == nil {
+				return uid
+			}
+		}
+	}
+	return -1
+}
+
+// lookupUid resolves the user name using the host's /etc/passwd, numeric uids are returned as is
+func lookupUid(user string) (int, error) {
+	if uid, err := strconv.Atoi(user); err == nil {
+		return uid, nil
+	}
+	passwd, err := os.ReadFile(hostfs.Path("/etc/passwd"))
+	if err != nil {
+		return -1, fmt.Errorf("failed to read users of the host: %w", err)
+	}
+	for _, line := range strings.Split(string(passwd), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) >= 3 && fields[0] == user {
+			return strconv.Atoi(fields[2])
+		}
+	}
+	return -1, fmt.Errorf("user %s not found on the host", user)
+}
@@ -1,9 +1,18 @@
 package stopprocess
 
 import (
+	"fmt"
+	"github.com/mitchellh/go-ps"
+	"github.com/steadybit/extension-host/exthost/hostfs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestStopProcesses(t *testing.T) {
@@ -16,3 +25,140 @@ func TestStopProcesses(t *testing.T) {
 	err = StopProcesses(ids, true)
 	assert.NoError(t, err)
 }
+
+type fakeProcess struct {
+	pid, ppid int
+	name      string
+}
+
+func (p fakeProcess) Pid() int           { return p.pid }
+func (p fakeProcess) PPid() int          { return p.ppid }
+func (p fakeProcess) Executable() string { return p.name }
+
+func fakeProcesses(t *testing.T) {
+	oldListProcesses, oldReadProcFile, oldListCgroupPids, oldRootPath := listProcesses, readProcFile, listCgroupPids, hostfs.RootPath
+	t.Cleanup(func() {
+		listProcesses, readProcFile, listCgroupPids, hostfs.RootPath = oldListProcesses, oldReadProcFile, oldListCgroupPids, oldRootPath
+	})
+	listCgroupPids = func(path string) ([]int, error) {
+		if path == "/system.slice/nginx.service" {
+			return []int{100, 101, 102}, nil
+		}
+		return nil, fmt.Errorf("cgroup %s not found", path)
+	}
+
+	listProcesses = func() ([]ps.Process, error) {
+		return []ps.Process{
+			fakeProcess{1, 0, "systemd"},
+			fakeProcess{100, 1, "nginx"},
+			fakeProcess{101, 100, "nginx"},
+			fakeProcess{102, 100, "nginx"},
+			fakeProcess{200, 1, "java"},
+		}, nil
+	}
+	files := map[string]string{
+		"1/cmdline":   "/sbin/init\x00",
+		"100/cmdline": "nginx: master process /usr/sbin/nginx\x00",
+		"101/cmdline": "nginx: worker process\x00",
+		"102/cmdline": "nginx: worker process\x00",
+		"200/cmdline": "java\x00-jar\x00/opt/app/orders.jar\x00",
+		"1/status":    "Name:\tsystemd\nUid:\t0\t0\t0\t0\n",
+		"100/status":  "Name:\tnginx\nUid:\t0\t0\t0\t0\n",
+		"101/status":  "Name:\tnginx\nUid:\t33\t33\t33\t33\n",
+		"102/status":  "Name:\tnginx\nUid:\t33\t33\t33\t33\n",
+		"200/status":  "Name:\tjava\nUid:\t1000\t1000\t1000\t1000\n",
+	}
+	readProcFile = func(pid int, name string) (string, error) {
+		if content, ok := files[fmt.Sprintf("%d/%s", pid, name)]; ok {
+			return content, nil
+		}
+		return "", os.ErrNotExist
+	}
+
+	hostfs.RootPath = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(hostfs.RootPath, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostfs.RootPath, "etc", "passwd"), []byte("root:x:0:0:root:/root:/bin/bash\nwww-data:x:33:33:www-data:/var/www:/usr/sbin/nologin\napp:x:1000:1000::/home/app:/bin/sh\n"), 0644))
+}
+
+func TestFindProcesses(t *testing.T) {
+	fakeProcesses(t)
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		want    []int
+		wantErr string
+	}{
+		{name: "executable", filter: Filter{Process: "ngin"}, want: []int{100, 101, 102}},
+		{name: "pid", filter: Filter{Process: "200"}, want: []int{200}},
+		{name: "cmdline regex", filter: Filter{Cmdline: `-jar .*orders\.jar$`}, want: []int{200}},
+		{name: "cmdline and executable", filter: Filter{Process: "nginx", Cmdline: "^nginx: worker"}, want: []int{101, 102}},
+		{name: "user name", filter: Filter{User: "www-data"}, want: []int{101, 102}},
+		{name: "uid", filter: Filter{User: "0", Process: "nginx"}, want: []int{100}},
+		{name: "parent pid", filter: Filter{ParentPid: 100}, want: []int{101, 102}},
+		{name: "cgroup", filter: Filter{Cgroup: "/system.slice/nginx.service"}, want: []int{100, 101, 102}},
+		{name: "cgroup and user", filter: Filter{Cgroup: "/system.slice/nginx.service", User: "www-data"}, want: []int{101, 102}},
+		{name: "no match", filter: Filter{Process: "java", User: "www-data"}},
+		{name: "unknown user", filter: Filter{User: "nobody"}, wantErr: "user nobody not found"},
+		{name: "invalid regex", filter: Filter{Cmdline: "("}, wantErr: "invalid command line pattern"},
+		{name: "empty filter", filter: Filter{}, wantErr: "no process filter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindProcesses(tt.filter)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.ErrorContains(t, tt.filter.Validate(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, tt.filter.Validate())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for name, want := range map[string]syscall.Signal{
+		"SIGHUP":  syscall.SIGHUP,
+		"hup":     syscall.SIGHUP,
+		"SIGSTOP": syscall.SIGSTOP,
+		"CONT":    syscall.SIGCONT,
+		"SIGSEGV": syscall.SIGSEGV,
+		"abrt":    syscall.SIGABRT,
+		"9":       syscall.SIGKILL,
+	} {
+		got, err := ParseSignal(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := ParseSignal("SIGFOO")
+	assert.ErrorContains(t, err, "unknown signal SIGFOO")
+	_, err = ParseSignal("999")
+	assert.ErrorContains(t, err, "unknown signal 999")
+}
+
+func TestSignalProcesses(t *testing.T) {
+	command := exec.Command("sleep", "60")
+	require.NoError(t, command.Start())
+
+	require.NoError(t, SignalProcesses([]int{command.Process.Pid}, syscall.SIGSTOP))
+	require.Eventually(t, func() bool {
+		return processState(command.Process.Pid) == "T"
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, SignalProcesses([]int{command.Process.Pid}, syscall.SIGCONT))
+	require.NoError(t, SignalProcesses([]int{command.Process.Pid}, syscall.SIGUSR1))
+	err := command.Wait()
+	assert.ErrorContains(t, err, "user defined signal 1")
+}
+
+func processState(pid int) string {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return fields[0]
+}
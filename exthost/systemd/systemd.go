@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-host/exthost/hostns"
+)
+
+var runSystemctl = func(ctx context.Context, arg ...string) (string, error) {
+	return hostns.Run(ctx, "systemctl", arg...)
+}
+
+var runSystemdRun = func(ctx context.Context, arg ...string) (string, error) {
+	return hostns.Run(ctx, "systemd-run", arg...)
+}
+
+// Booted reports whether the host runs systemd, using the same check as sd_booted(3).
+func Booted() bool {
+	_, err := os.Stat(hostfs.Path("/run/systemd/system"))
+	return err == nil
+}
+
+// Show returns the requested properties of the unit.
+func Show(ctx context.Context, unit string, properties ...string) (map[string]string, error) {
+	out, err := runSystemctl(ctx, "show", "--property="+strings.Join(properties, ","), "--", unit)
+	if err != nil {
+		return nil, err
+	}
+	return parseProperties(out), nil
+}
+
+// ControlGroup returns the cgroup path of the unit. Fails if the unit is not running.
+func ControlGroup(ctx context.Context, unit string) (string, error) {
+	props, err := Show(ctx, unit, "ControlGroup")
+	if err != nil {
+		return "", err
+	}
+	if props["ControlGroup"] == "" {
+		return "", fmt.Errorf("unit %s has no control group, is it running?", unit)
+	}
+	return props["ControlGroup"], nil
+}
+
+// ExecStartLine returns the ExecStart line of the service as written in its unit files, including drop-ins. Reusing the line
+// lets systemd apply its own quoting and variable expansion, which is lost in the ExecStart property shown by systemctl.
+func ExecStartLine(ctx context.Context, unit string) (string, error) {
+	out, err := runSystemctl(ctx, "cat", "--", unit)
+	if err != nil {
+		return "", err
+	}
+	line, err := parseExecStartLine(out)
+	if err != nil {
+		return "", fmt.Errorf("unit %s: %w", unit, err)
+	}
+	return line, nil
+}
+
+// ActiveState returns the active state of the unit, e.g. active, inactive or failed.
+func ActiveState(ctx context.Context, unit string) (string, error) {
+	props, err := Show(ctx, unit, "ActiveState")
+	if err != nil {
+		return "", err
+	}
+	return props["ActiveState"], nil
+}
+
+// StartUnit starts the unit and waits for the start job to complete.
+func StartUnit(ctx context.Context, unit string) error {
+	_, err := runSystemctl(ctx, "start", "--", unit)
+	return err
+}
+
+// StopUnit stops the unit and waits for the stop job to complete.
+func StopUnit(ctx context.Context, unit string) error {
+	_, err := runSystemctl(ctx, "stop", "--", unit)
+	return err
+}
+
+// KillUnit sends the signal to all processes of the unit. systemd treats this as a failure of the unit and applies its Restart policy.
+func KillUnit(ctx context.Context, unit string, signal string) error {
+	_, err := runSystemctl(ctx, "kill", "--signal="+signal, "--", unit)
+	return err
+}
+
+// MaskUnit masks the unit until the next reboot, so it can't be started until it is unmasked.
+func MaskUnit(ctx context.Context, unit string) error {
+	_, err := runSystemctl(ctx, "mask", "--runtime", "--", unit)
+	return err
+}
+
+// RestartUnit restarts the unit, it is started if it is not running.
+func RestartUnit(ctx context.Context, unit string) error {
+	_, err := runSystemctl(ctx, "restart", "--", unit)
+	return err
+}
+
+// ResetFailed unloads a failed unit, e.g. a failed transient service.
+func ResetFailed(ctx context.Context, unit string) error {
+	_, err := runSystemctl(ctx, "reset-failed", "--", unit)
+	return err
+}
+
+// DaemonReload makes systemd read changed unit files and drop-ins.
+func DaemonReload(ctx context.Context) error {
+	_, err := runSystemctl(ctx, "daemon-reload")
+	return err
+}
+
+// RuntimeDropInPath returns the path of a drop-in of the unit which is lost on reboot.
+func RuntimeDropInPath(unit, name string) string {
+	return fmt.Sprintf("/run/systemd/system/%s.d/%s.conf", unit, name)
+}
+
+var writeHostFile = func(ctx context.Context, path, content string) error {
+	cmd := hostns.Command(ctx, "sh", "-c", `mkdir -p -- "$(dirname -- "$1")" && cat > "$1" && chmod 0644 -- "$1"`, "sh", path)
+	cmd.Stdin = strings.NewReader(content)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to write %s: %w: %s", path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+var removeHostFile = func(ctx context.Context, path string) error {
+	_, err := hostns.Run(ctx, "rm", "-f", "--", path)
+	return err
+}
+
+// WriteRuntimeDropIn writes the drop-in of the unit as root, DaemonReload has to be called to apply it.
+func WriteRuntimeDropIn(ctx context.Context, unit, name, content string) error {
+	return writeHostFile(ctx, RuntimeDropInPath(unit, name), content)
+}
+
+// RemoveRuntimeDropIn removes a drop-in written by WriteRuntimeDropIn, DaemonReload has to be called to apply it.
+func RemoveRuntimeDropIn(ctx context.Context, unit, name string) error {
+	return removeHostFile(ctx, RuntimeDropInPath(unit, name))
+}
+
+// UnmaskUnit removes a mask created by MaskUnit.
+func UnmaskUnit(ctx context.Context, unit string) error {
+	_, err := runSystemctl(ctx, "unmask", "--runtime", "--", unit)
+	return err
+}
+
+// Service describes a loaded systemd service as shown by ListServices.
+type Service struct {
+	Name             string
+	Description      string
+	ActiveState      string
+	ControlGroup     string
+	MemoryAccounting bool
+	CPUAccounting    bool
+}
+
+var serviceProperties = []string{"Id", "Description", "ActiveState", "ControlGroup", "MemoryAccounting", "CPUAccounting"}
+
+// ListServices returns the loaded services which are active, failed or have a pending job.
+func ListServices(ctx context.Context) ([]Service, error) {
+	out, err := runSystemctl(ctx, "list-units", "--type=service", "--plain", "--no-legend", "--no-pager")
+	if err != nil {
+		return nil, err
+	}
+	var units []string
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			units = append(units, fields[0])
+		}
+	}
+	if len(units) == 0 {
+		return nil, nil
+	}
+
+	out, err = runSystemctl(ctx, append([]string{"show", "--property=" + strings.Join(serviceProperties, ","), "--"}, units...)...)
+	if err != nil {
+		return nil, err
+	}
+	var services []Service
+	for _, block := range strings.Split(strings.TrimSpace(out), "\n\n") {
+		props := parseProperties(block)
+		if props["Id"] == "" {
+			continue
+		}
+		services = append(services, Service{
+			Name:             props["Id"],
+			Description:      props["Description"],
+			ActiveState:      props["ActiveState"],
+			ControlGroup:     props["ControlGroup"],
+			MemoryAccounting: props["MemoryAccounting"] == "yes",
+			CPUAccounting:    props["CPUAccounting"] == "yes",
+		})
+	}
+	return services, nil
+}
+
+// Usage is the resource usage of a unit, the values are nil if accounting is disabled or the unit isn't running.
+type Usage struct {
+	MemoryCurrent *uint64
+	CPUUsageNSec  *uint64
+}
+
+// ReadUsage returns the current memory and the CPU time consumed by the unit.
+func ReadUsage(ctx context.Context, unit string) (Usage, error) {
+	props, err := Show(ctx, unit, "MemoryCurrent", "CPUUsageNSec")
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{
+		MemoryCurrent: parseCounter(props["MemoryCurrent"]),
+		CPUUsageNSec:  parseCounter(props["CPUUsageNSec"]),
+	}, nil
+}
+
+// parseCounter parses an accounting property, systemd reports "[not set]" or the max uint64 if it is not available
+func parseCounter(s string) *uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || v == math.MaxUint64 {
+		return nil
+	}
+	return &v
+}
+
+// TransientService configures a service started by RunTransient.
+type TransientService struct {
+	Unit string
+}
+
+// RunTransient starts the command as a transient service. It is garbage collected once it exited successfully, a failed
+// service is kept to read its result until ResetFailed is called.
+func RunTransient(ctx context.Context, service TransientService, argv ...string) error {
+	args := []string{"--unit", service.Unit, "--service-type=exec"}
+	_, err := runSystemdRun(ctx, append(append(args, "--"), argv...)...)
+	return err
+}
+
+// parseExecStartLine returns the effective ExecStart of the [Service] sections in the output of systemctl cat.
+// An empty assignment resets the commands defined before, like systemd does for drop-ins.
+func parseExecStartLine(s string) (string, error) {
+	var commands []string
+	inService := false
+	var pending string
+	for _, raw := range strings.Split(s, "\n") {
+		// a trailing backslash continues the line, systemd replaces it with a space
+		if strings.HasSuffix(raw, "\\") {
+			pending += strings.TrimSuffix(raw, "\\") + " "
+			continue
+		}
+		line := strings.TrimSpace(pending + raw)
+		pending = ""
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inService = line == "[Service]"
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !inService || !found || strings.TrimSpace(key) != "ExecStart" {
+			continue
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			commands = nil
+		} else {
+			commands = append(commands, value)
+		}
+	}
+	switch len(commands) {
+	case 0:
+		return "", fmt.Errorf("no ExecStart command found")
+	case 1:
+		return commands[0], nil
+	default:
+		return "", fmt.Errorf("multiple ExecStart commands are not supported")
+	}
+}
+
+func parseProperties(s string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if found {
+			result[key] = value
+		}
+	}
+	return result
+}
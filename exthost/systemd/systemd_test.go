@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package systemd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeSystemctl(t *testing.T, fn func(arg ...string) (string, error)) {
+	oldRunSystemctl := runSystemctl
+	runSystemctl = func(ctx context.Context, arg ...string) (string, error) {
+		return fn(arg...)
+	}
+	t.Cleanup(func() {
+		runSystemctl = oldRunSystemctl
+	})
+}
+
+func TestShow(t *testing.T) {
+	var args []string
+	fakeSystemctl(t, func(arg ...string) (string, error) {
+		args = arg
+		return "ActiveState=active\nControlGroup=/system.slice/nginx.service\nExecStart={ path=/usr/sbin/nginx ; argv[]=/usr/sbin/nginx -g daemon on; }\n", nil
+	})
+
+	props, err := Show(context.Background(), "nginx.service", "ActiveState", "ControlGroup", "ExecStart")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"show", "--property=ActiveState,ControlGroup,ExecStart", "--", "nginx.service"}, args)
+	assert.Equal(t, "active", props["ActiveState"])
+	assert.Equal(t, "/system.slice/nginx.service", props["ControlGroup"])
+	assert.Equal(t, "{ path=/usr/sbin/nginx ; argv[]=/usr/sbin/nginx -g daemon on; }", props["ExecStart"])
+}
+
+func TestControlGroup(t *testing.T) {
+	fakeSystemctl(t, func(arg ...string) (string, error) {
+		return "ControlGroup=\n", nil
+	})
+	_, err := ControlGroup(context.Background(), "nginx.service")
+	assert.ErrorContains(t, err, "is it running?")
+}
+
+func Test_parseExecStartLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "quoting is kept",
+			value: "# /lib/systemd/system/nginx.service\n[Unit]\nDescription=nginx\n\n[Service]\nType=forking\nExecStartPre=/usr/sbin/nginx -t\nExecStart=/usr/sbin/nginx -g 'daemon on; master_process on;'\n",
+			want:  "/usr/sbin/nginx -g 'daemon on; master_process on;'",
+		},
+		{
+			name:  "drop-in resets the command",
+			value: "# /lib/systemd/system/app.service\n[Service]\nExecStart=/usr/bin/app\n\n# /etc/systemd/system/app.service.d/override.conf\n[Service]\nExecStart=\nExecStart=-/usr/bin/app --config \"/etc/app/my app.conf\"\n",
+			want:  `-/usr/bin/app --config "/etc/app/my app.conf"`,
+		},
+		{
+			name:  "continued line",
+			value: "[Service]\nExecStart=/usr/bin/app \\\n  --verbose\n",
+			want:  "/usr/bin/app    --verbose",
+		},
+		{
+			name:  "other sections are ignored",
+			value: "[Unit]\nExecStart=/bin/wrong\n[Service]\nExecStart=/bin/right\n[Install]\nWantedBy=multi-user.target\n",
+			want:  "/bin/right",
+		},
+		{
+			name:    "multiple commands",
+			value:   "[Service]\nType=oneshot\nExecStart=/bin/a\nExecStart=/bin/b\n",
+			wantErr: "multiple ExecStart commands",
+		},
+		{
+			name:    "no command",
+			value:   "[Service]\nType=oneshot\n",
+			wantErr: "no ExecStart command",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExecStartLine(tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRuntimeDropIn(t *testing.T) {
+	oldWriteHostFile, oldRemoveHostFile := writeHostFile, removeHostFile
+	t.Cleanup(func() {
+		writeHostFile, removeHostFile = oldWriteHostFile, oldRemoveHostFile
+	})
+	files := make(map[string]string)
+	writeHostFile = func(ctx context.Context, path, content string) error {
+		files[path] = content
+		return nil
+	}
+	removeHostFile = func(ctx context.Context, path string) error {
+		delete(files, path)
+		return nil
+	}
+
+	require.NoError(t, WriteRuntimeDropIn(context.Background(), "nginx.service", "zz-test", "[Service]\n"))
+	assert.Equal(t, map[string]string{"/run/systemd/system/nginx.service.d/zz-test.conf": "[Service]\n"}, files)
+	require.NoError(t, RemoveRuntimeDropIn(context.Background(), "nginx.service", "zz-test"))
+	assert.Empty(t, files)
+}
+
+func TestRunTransient(t *testing.T) {
+	oldRunSystemdRun := runSystemdRun
+	t.Cleanup(func() {
+		runSystemdRun = oldRunSystemdRun
+	})
+	var args []string
+	runSystemdRun = func(ctx context.Context, arg ...string) (string, error) {
+		args = arg
+		return "", nil
+	}
+
+	err := RunTransient(context.Background(), TransientService{Unit: "test.service"}, "sleep", "infinity")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--unit", "test.service", "--service-type=exec", "--", "sleep", "infinity"}, args)
+}
+
+func TestUnitCommands(t *testing.T) {
+	var calls [][]string
+	fakeSystemctl(t, func(arg ...string) (string, error) {
+		calls = append(calls, arg)
+		return "", nil
+	})
+
+	require.NoError(t, KillUnit(context.Background(), "nginx.service", "SIGKILL"))
+	require.NoError(t, MaskUnit(context.Background(), "nginx.service"))
+	require.NoError(t, UnmaskUnit(context.Background(), "nginx.service"))
+	require.NoError(t, RestartUnit(context.Background(), "nginx.service"))
+	require.NoError(t, ResetFailed(context.Background(), "test.service"))
+	require.NoError(t, DaemonReload(context.Background()))
+	assert.Equal(t, [][]string{
+		{"kill", "--signal=SIGKILL", "--", "nginx.service"},
+		{"mask", "--runtime", "--", "nginx.service"},
+		{"unmask", "--runtime", "--", "nginx.service"},
+		{"restart", "--", "nginx.service"},
+		{"reset-failed", "--", "test.service"},
+		{"daemon-reload"},
+	}, calls)
+}
+
+func TestListServices(t *testing.T) {
+	var calls [][]string
+	fakeSystemctl(t, func(arg ...string) (string, error) {
+		calls = append(calls, arg)
+		if arg[0] == "list-units" {
+			return "cron.service    loaded active running Regular background program processing daemon\nnginx.service   loaded failed failed  A high performance web server\n", nil
+		}
+		return "Id=cron.service\nDescription=Regular background program processing daemon\nActiveState=active\nControlGroup=/system.slice/cron.service\nMemoryAccounting=yes\nCPUAccounting=yes\n\n" +
+			"Id=nginx.service\nDescription=A high performance web server\nActiveState=failed\nControlGroup=\nMemoryAccounting=yes\nCPUAccounting=no\n", nil
+	})
+
+	services, err := ListServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"show", "--property=Id,Description,ActiveState,ControlGroup,MemoryAccounting,CPUAccounting", "--", "cron.service", "nginx.service"}, calls[1])
+	require.Len(t, services, 2)
+	assert.Equal(t, Service{
+		Name:             "cron.service",
+		Description:      "Regular background program processing daemon",
+		ActiveState:      "active",
+		ControlGroup:     "/system.slice/cron.service",
+		MemoryAccounting: true,
+		CPUAccounting:    true,
+	}, services[0])
+	assert.Equal(t, "failed", services[1].ActiveState)
+	assert.False(t, services[1].CPUAccounting)
+}
+
+func TestReadUsage(t *testing.T) {
+	var calls [][]string
+	fakeSystemctl(t, func(arg ...string) (string, error) {
+		calls = append(calls, arg)
+		return "MemoryCurrent=1474560\nCPUUsageNSec=31427000\n", nil
+	})
+	usage, err := ReadUsage(context.Background(), "cron.service")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"show", "--property=MemoryCurrent,CPUUsageNSec", "--", "cron.service"}, calls[0])
+	assert.Equal(t, Usage{MemoryCurrent: extutil.Ptr(uint64(1474560)), CPUUsageNSec: extutil.Ptr(uint64(31427000))}, usage)
+
+	fakeSystemctl(t, func(arg ...string) (string, error) {
+		return "MemoryCurrent=[not set]\nCPUUsageNSec=18446744073709551615\n", nil
+	})
+	usage, err = ReadUsage(context.Background(), "nginx.service")
+	require.NoError(t, err)
+	assert.Nil(t, usage.MemoryCurrent)
+	assert.Nil(t, usage.CPUUsageNSec)
+}
+
+func TestListServicesWithoutUnits(t *testing.T) {
+	fakeSystemctl(t, func(arg ...string) (string, error) {
+		return "", nil
+	})
+	services, err := ListServices(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, services)
+}
+
+func TestBooted(t *testing.T) {
+	oldRootPath := hostfs.RootPath
+	hostfs.RootPath = t.TempDir()
+	t.Cleanup(func() {
+		hostfs.RootPath = oldRootPath
+	})
+	assert.False(t, Booted())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(hostfs.RootPath, "run", "systemd", "system"), 0755))
+	assert.True(t, Booted())
+}
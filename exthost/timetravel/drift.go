@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// userHz is the kernel's USER_HZ, the tick is the number of microseconds added to the clock per 1/USER_HZ second
+	userHz = 100
+	// ppmPerTick is the rate change of one microsecond tick adjustment
+	ppmPerTick = 1_000_000 / (1_000_000 / userHz)
+	// freqScale is the scale of the adjtimex frequency, which is given in 2^-16 ppm
+	freqScale = 1 << 16
+	// maxFreqPpm is the maximum frequency adjustment accepted by the kernel
+	maxFreqPpm = 500
+	minTick    = 900_000 / userHz
+	maxTick    = 1_100_000 / userHz
+)
+
+// ClockAdjustment holds the raw tick and frequency adjustment of the kernel clock
+type ClockAdjustment struct {
+	Tick int64
+	Freq int64
+}
+
+// GetClockAdjustment reads the current tick and frequency adjustment
+func GetClockAdjustment() (ClockAdjustment, error) {
+	tx := unix.Timex{}
+	if _, err := unix.Adjtimex(&tx); err != nil {
+		return ClockAdjustment{}, fmt.Errorf("failed to read clock adjustment: %w", err)
+	}
+	return ClockAdjustment{Tick: tx.Tick, Freq: tx.Freq}, nil
+}
+
+// SetClockAdjustment sets the tick and frequency adjustment
+func SetClockAdjustment(adj ClockAdjustment) error {
+	tx := unix.Timex{
+		Modes: unix.ADJ_TICK | unix.ADJ_FREQUENCY,
+		Tick:  adj.Tick,
+		Freq:  adj.Freq,
+	}
+	if _, err := unix.Adjtimex(&tx); err != nil {
+		return fmt.Errorf("failed to set clock adjustment tick=%d freq=%d: %w", adj.Tick, adj.Freq, err)
+	}
+	return nil
+}
+
+// DriftAdjustment returns the adjustment letting the clock drift by the given ppm on top of the original adjustment.
+// Positive values make the clock run fast. As the frequency is limited to ±500 ppm, whole multiples of 100 ppm are
+// applied by the tick, allowing up to about ±10% drift.
+func DriftAdjustment(original ClockAdjustment, ppm int64) (ClockAdjustment, error) {
+	adj := ClockAdjustment{
+		Tick: original.Tick + ppm/ppmPerTick,
+		Freq: original.Freq + (ppm%ppmPerTick)*freqScale,
+	}
+	if adj.Freq > maxFreqPpm*freqScale {
+		adj.Tick++
+		adj.Freq -= ppmPerTick * freqScale
+	} else if adj.Freq < -maxFreqPpm*freqScale {
+		adj.Tick--
+		adj.Freq += ppmPerTick * freqScale
+	}
+	if adj.Tick < minTick || adj.Tick > maxTick {
+		return ClockAdjustment{}, fmt.Errorf("drift of %d ppm exceeds the supported range of the clock", ppm)
+	}
+	return adj, nil
+}
+
+// StartDrift lets the clock drift by the given ppm and returns the original adjustment to be restored
+func StartDrift(ppm int64) (ClockAdjustment, error) {
+	original, err := GetClockAdjustment()
+	if err != nil {
+		return ClockAdjustment{}, err
+	}
+	adj, err := DriftAdjustment(original, ppm)
+	if err != nil {
+		return ClockAdjustment{}, err
+	}
+	log.Info().Int64("ppm", ppm).Int64("tick", adj.Tick).Int64("freq", adj.Freq).Msg("Letting the clock drift")
+	return original, SetClockAdjustment(adj)
+}
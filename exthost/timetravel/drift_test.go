@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftAdjustment(t *testing.T) {
+	nominal := ClockAdjustment{Tick: 10000, Freq: 0}
+	tests := []struct {
+		name     string
+		original ClockAdjustment
+		ppm      int64
+		want     ClockAdjustment
+		wantErr  bool
+	}{
+		{name: "no drift", original: nominal, ppm: 0, want: nominal},
+		{name: "frequency only", original: nominal, ppm: 50, want: ClockAdjustment{Tick: 10000, Freq: 50 << 16}},
+		{name: "tick and frequency", original: nominal, ppm: 1234, want: ClockAdjustment{Tick: 10012, Freq: 34 << 16}},
+		{name: "slow clock", original: nominal, ppm: -1234, want: ClockAdjustment{Tick: 9988, Freq: -34 << 16}},
+		{name: "on top of ntp frequency", original: ClockAdjustment{Tick: 10000, Freq: 480 << 16}, ppm: 50, want: ClockAdjustment{Tick: 10001, Freq: 430 << 16}},
+		{name: "below ntp frequency", original: ClockAdjustment{Tick: 10000, Freq: -480 << 16}, ppm: -50, want: ClockAdjustment{Tick: 9999, Freq: -430 << 16}},
+		{name: "maximum", original: nominal, ppm: 100_000, want: ClockAdjustment{Tick: 11000, Freq: 0}},
+		{name: "too fast", original: nominal, ppm: 100_100, wantErr: true},
+		{name: "too slow", original: nominal, ppm: -100_100, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DriftAdjustment(tt.original, tt.ppm)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// InsertLeapSecond arms the kernel to insert a leap second at the next UTC midnight and returns the original clock status
+func InsertLeapSecond() (int32, error) {
+	tx := unix.Timex{}
+	if _, err := unix.Adjtimex(&tx); err != nil {
+		return 0, fmt.Errorf("failed to read clock status: %w", err)
+	}
+	original := tx.Status
+
+	tx = unix.Timex{Modes: unix.ADJ_STATUS, Status: withLeapInsert(original)}
+	if _, err := unix.Adjtimex(&tx); err != nil {
+		return 0, fmt.Errorf("failed to arm leap second: %w", err)
+	}
+	return original, nil
+}
+
+// ClearLeapSecond disarms a pending leap second, restoring the leap flags of the original status
+func ClearLeapSecond(original int32) error {
+	tx := unix.Timex{}
+	if _, err := unix.Adjtimex(&tx); err != nil {
+		return fmt.Errorf("failed to read clock status: %w", err)
+	}
+	tx = unix.Timex{Modes: unix.ADJ_STATUS, Status: withLeapFlags(tx.Status, original)}
+	if _, err := unix.Adjtimex(&tx); err != nil {
+		return fmt.Errorf("failed to clear leap second: %w", err)
+	}
+	return nil
+}
+
+// NextLeapSecond returns the moment the kernel inserts an armed leap second, the next midnight UTC
+func NextLeapSecond(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+func withLeapInsert(status int32) int32 {
+	return status&^unix.STA_DEL | unix.STA_INS
+}
+
+func withLeapFlags(status, original int32) int32 {
+	return status&^(unix.STA_INS|unix.STA_DEL) | original&(unix.STA_INS|unix.STA_DEL)
+}
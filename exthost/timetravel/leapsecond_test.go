@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestNextLeapSecond(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), NextLeapSecond(time.Date(2025, 6, 30, 23, 59, 50, 0, time.UTC)))
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), NextLeapSecond(time.Date(2025, 7, 1, 1, 30, 0, 0, berlin)))
+	assert.Equal(t, time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), NextLeapSecond(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func Test_leapFlags(t *testing.T) {
+	assert.Equal(t, int32(unix.STA_PLL|unix.STA_INS), withLeapInsert(unix.STA_PLL|unix.STA_DEL))
+	assert.Equal(t, int32(unix.STA_PLL), withLeapFlags(unix.STA_PLL|unix.STA_INS, unix.STA_UNSYNC))
+	assert.Equal(t, int32(unix.STA_PLL|unix.STA_DEL), withLeapFlags(unix.STA_PLL|unix.STA_INS, unix.STA_DEL))
+}
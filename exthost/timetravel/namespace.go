@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// NamespaceSupported reports whether the kernel supports time namespaces (Linux 5.6+)
+func NamespaceSupported() bool {
+	_, err := os.Stat("/proc/self/ns/time")
+	return err == nil
+}
+
+// NamespaceOffset returns the offset in whole seconds for CLOCK_MONOTONIC and CLOCK_BOOTTIME of a new time namespace.
+// The clocks must not become negative, so a backward offset is limited by the uptime of the host.
+func NamespaceOffset(offset time.Duration) (int64, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0, fmt.Errorf("failed to read monotonic clock: %w", err)
+	}
+	return namespaceOffset(offset, time.Duration(ts.Nano()))
+}
+
+func namespaceOffset(offset, monotonic time.Duration) (int64, error) {
+	seconds := int64(offset / time.Second)
+	if seconds == 0 {
+		return 0, fmt.Errorf("the offset of a time namespace must be at least 1s, got %s", offset)
+	}
+	if offset < 0 && -offset >= monotonic {
+		return 0, fmt.Errorf("the offset %s exceeds the uptime of the host of %s", offset, monotonic.Truncate(time.Second))
+	}
+	return seconds, nil
+}
+
+// NamespaceCommand returns the command running argv in a new time namespace with the given offset in seconds.
+// If user is set, the privileges are dropped to the user after entering the namespace, as creating it requires root.
+func NamespaceCommand(seconds int64, user, group string, argv []string) []string {
+	offset := strconv.FormatInt(seconds, 10)
+	cmd := []string{"unshare", "--time", "--fork", "--monotonic", offset, "--boottime", offset, "--"}
+	if user != "" && user != "root" {
+		cmd = append(cmd, "setpriv", "--reuid", user, "--init-groups")
+		if group != "" {
+			cmd = append(cmd, "--regid", group)
+		} else {
+			cmd = append(cmd, "--regid", user)
+		}
+		cmd = append(cmd, "--")
+	}
+	return append(cmd, argv...)
+}
+
+// NamespaceDropIn returns a drop-in running the service's ExecStart line in a new time namespace. Creating the namespace
+// requires root, so the line runs with full privileges and drops them to the unit's user. The environment, working directory
+// and limits of the unit still apply, its sandboxing does not.
+func NamespaceDropIn(execStart string, seconds int64, user, group string) (string, error) {
+	command := strings.TrimLeft(execStart, "@-:+!")
+	prefixes := execStart[:len(execStart)-len(command)]
+	if strings.Contains(prefixes, "@") {
+		return "", fmt.Errorf("ExecStart with the '@' prefix is not supported")
+	}
+	var prefix string
+	if strings.Contains(prefixes, "-") {
+		prefix += "-"
+	}
+	if strings.Contains(prefixes, ":") {
+		prefix += ":"
+	}
+	if strings.Contains(prefixes, "+") {
+		// the command ran with full privileges already, User= didn't apply to it
+		user, group = "", ""
+	}
+
+	// unshare forks, the service notifies systemd from the child instead of the main process
+	return fmt.Sprintf("[Service]\nNotifyAccess=all\nExecStart=\nExecStart=%s+%s %s\n", prefix, strings.Join(NamespaceCommand(seconds, user, group, nil), " "), command), nil
+}
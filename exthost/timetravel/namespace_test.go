@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_namespaceOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		offset  time.Duration
+		want    int64
+		wantErr string
+	}{
+		{name: "forward", offset: time.Hour, want: 3600},
+		{name: "fraction is truncated", offset: 2500 * time.Millisecond, want: 2},
+		{name: "backward", offset: -time.Hour, want: -3600},
+		{name: "below one second", offset: 999 * time.Millisecond, wantErr: "at least 1s"},
+		{name: "backward beyond uptime", offset: -48 * time.Hour, wantErr: "exceeds the uptime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := namespaceOffset(tt.offset, 24*time.Hour)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNamespaceCommand(t *testing.T) {
+	assert.Equal(t,
+		[]string{"unshare", "--time", "--fork", "--monotonic", "-60", "--boottime", "-60", "--", "sh", "-c", "date"},
+		NamespaceCommand(-60, "", "", []string{"sh", "-c", "date"}),
+	)
+	assert.Equal(t,
+		[]string{"unshare", "--time", "--fork", "--monotonic", "3600", "--boottime", "3600", "--", "setpriv", "--reuid", "www-data", "--init-groups", "--regid", "www", "--", "/usr/sbin/nginx"},
+		NamespaceCommand(3600, "www-data", "www", []string{"/usr/sbin/nginx"}),
+	)
+	assert.Equal(t,
+		[]string{"unshare", "--time", "--fork", "--monotonic", "1", "--boottime", "1", "--", "setpriv", "--reuid", "app", "--init-groups", "--regid", "app", "--", "app"},
+		NamespaceCommand(1, "app", "", []string{"app"}),
+	)
+}
+
+func TestNamespaceDropIn(t *testing.T) {
+	tests := []struct {
+		name      string
+		execStart string
+		user      string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "quoting is kept",
+			execStart: "/usr/sbin/nginx -g 'daemon on; master_process on;'",
+			want:      "[Service]\nNotifyAccess=all\nExecStart=\nExecStart=+unshare --time --fork --monotonic 60 --boottime 60 -- /usr/sbin/nginx -g 'daemon on; master_process on;'\n",
+		},
+		{
+			name:      "privileges are dropped to the user",
+			execStart: "-/usr/bin/app $OPTS",
+			user:      "app",
+			want:      "[Service]\nNotifyAccess=all\nExecStart=\nExecStart=-+unshare --time --fork --monotonic 60 --boottime 60 -- setpriv --reuid app --init-groups --regid app -- /usr/bin/app $OPTS\n",
+		},
+		{
+			name:      "privileged command ignores the user",
+			execStart: "+/usr/bin/app",
+			user:      "app",
+			want:      "[Service]\nNotifyAccess=all\nExecStart=\nExecStart=+unshare --time --fork --monotonic 60 --boottime 60 -- /usr/bin/app\n",
+		},
+		{
+			name:      "argv0 can't be set",
+			execStart: "@/usr/bin/app app-name",
+			wantErr:   "'@' prefix is not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NamespaceDropIn(tt.execStart, 60, tt.user, "")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
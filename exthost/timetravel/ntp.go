@@ -5,16 +5,65 @@ package timetravel
 
 import (
 	"context"
+	"fmt"
+	"strings"
+
 	"github.com/steadybit/action-kit/go/action_kit_commons/network"
 )
 
-func AdjustNtpTrafficRules(ctx context.Context, runner network.CommandRunner, allowNtpTraffic bool) error {
-	opts := &network.BlackholeOpts{
-		IpProto: network.IpProtoUdp,
-		Filter: network.Filter{
-			Include: network.NewNetWithPortRanges(network.NetAny, network.PortRange{From: 123, To: 123}),
+// timeSyncOpts blocks NTP (udp/123), PTP (udp/319-320) and NTS key establishment (tcp/4460).
+// The rules are applied as a single network attack, as only one attack is supported per network namespace.
+type timeSyncOpts []network.BlackholeOpts
+
+func newTimeSyncOpts() timeSyncOpts {
+	return timeSyncOpts{
+		{
+			IpProto: network.IpProtoUdp,
+			Filter: network.Filter{
+				Include: network.NewNetWithPortRanges(network.NetAny, network.PortRange{From: 123, To: 123}, network.PortRange{From: 319, To: 320}),
+			},
+		},
+		{
+			IpProto: network.IpProtoTcp,
+			Filter: network.Filter{
+				Include: network.NewNetWithPortRanges(network.NetAny, network.PortRange{From: 4460, To: 4460}),
+			},
 		},
 	}
+}
+
+func (o timeSyncOpts) IpCommands(family network.Family, mode network.Mode) ([]string, error) {
+	var cmds []string
+	for i := range o {
+		// delete in reverse order of adding
+		opts := o[i]
+		if mode == network.ModeDelete {
+			opts = o[len(o)-1-i]
+		}
+		c, err := opts.IpCommands(family, mode)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, c...)
+	}
+	return cmds, nil
+}
+
+func (o timeSyncOpts) TcCommands(_ network.Mode) ([]string, error) {
+	return nil, nil
+}
+
+func (o timeSyncOpts) String() string {
+	var s []string
+	for i := range o {
+		s = append(s, o[i].String())
+	}
+	return fmt.Sprintf("time sync: %s", strings.Join(s, ", "))
+}
+
+// AdjustNtpTrafficRules blocks or allows the traffic of time synchronization protocols
+func AdjustNtpTrafficRules(ctx context.Context, runner network.CommandRunner, allowNtpTraffic bool) error {
+	opts := newTimeSyncOpts()
 
 	if allowNtpTraffic {
 		return network.Revert(ctx, runner, opts)
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"testing"
+
+	"github.com/steadybit/action-kit/go/action_kit_commons/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_timeSyncOpts(t *testing.T) {
+	opts := newTimeSyncOpts()
+
+	add, err := opts.IpCommands(network.FamilyV4, network.ModeAdd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"rule add blackhole to 0.0.0.0/0 ipproto udp dport 123",
+		"rule add blackhole from 0.0.0.0/0 ipproto udp sport 123",
+		"rule add blackhole to 0.0.0.0/0 ipproto udp dport 319-320",
+		"rule add blackhole from 0.0.0.0/0 ipproto udp sport 319-320",
+		"rule add blackhole to 0.0.0.0/0 ipproto tcp dport 4460",
+		"rule add blackhole from 0.0.0.0/0 ipproto tcp sport 4460",
+	}, add)
+
+	del, err := opts.IpCommands(network.FamilyV4, network.ModeDelete)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"rule del blackhole from 0.0.0.0/0 ipproto tcp sport 4460",
+		"rule del blackhole to 0.0.0.0/0 ipproto tcp dport 4460",
+		"rule del blackhole from 0.0.0.0/0 ipproto udp sport 319-320",
+		"rule del blackhole to 0.0.0.0/0 ipproto udp dport 319-320",
+		"rule del blackhole from 0.0.0.0/0 ipproto udp sport 123",
+		"rule del blackhole to 0.0.0.0/0 ipproto udp dport 123",
+	}, del)
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Reference pairs the wall clock with a clock not affected by the attack, to compute the correct wall time later.
+// CLOCK_MONOTONIC isn't affected by setting the time, but is slewed by frequency adjustments like CLOCK_REALTIME,
+// so CLOCK_MONOTONIC_RAW must be used when the frequency is changed.
+type Reference struct {
+	WallTime   time.Time
+	ClockId    int32
+	ClockNanos int64
+}
+
+// NewReference reads the wall clock and the given clock as close together as possible
+func NewReference(clockId int32) (Reference, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(clockId, &ts); err != nil {
+		return Reference{}, fmt.Errorf("failed to read clock %d: %w", clockId, err)
+	}
+	// strip the monotonic reading, otherwise comparisons with the wall time would use Go's monotonic clock
+	return Reference{WallTime: time.Now().Round(0), ClockId: clockId, ClockNanos: ts.Nano()}, nil
+}
+
+// Elapsed returns the time elapsed since the reference according to the reference clock
+func (r Reference) Elapsed() (time.Duration, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(r.ClockId, &ts); err != nil {
+		return 0, fmt.Errorf("failed to read clock %d: %w", r.ClockId, err)
+	}
+	return time.Duration(ts.Nano() - r.ClockNanos), nil
+}
+
+// Now returns the correct wall time, the wall time of the reference plus the elapsed time
+func (r Reference) Now() (time.Time, error) {
+	elapsed, err := r.Elapsed()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.WallTime.Add(elapsed), nil
+}
+
+// SetTime steps the wall clock to the given time
+func SetTime(t time.Time) error {
+	ts := unix.NsecToTimespec(t.UnixNano())
+	if err := unix.ClockSettime(unix.CLOCK_REALTIME, &ts); err != nil {
+		return fmt.Errorf("failed to set time: %w", err)
+	}
+	return nil
+}
+
+// RestoreTime steps the wall clock to the correct time according to the reference.
+// It returns the step applied and the residual error between the wall clock and the reference afterwards.
+func RestoreTime(r Reference) (step time.Duration, residual time.Duration, err error) {
+	correct, err := r.Now()
+	if err != nil {
+		return 0, 0, err
+	}
+	step = time.Duration(correct.UnixNano() - time.Now().UnixNano())
+	if err := SetTime(correct); err != nil {
+		return 0, 0, err
+	}
+
+	expected, err := r.Now()
+	if err != nil {
+		return step, 0, err
+	}
+	return step, time.Duration(time.Now().UnixNano() - expected.UnixNano()), nil
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestReference(t *testing.T) {
+	for _, clockId := range []int32{unix.CLOCK_MONOTONIC, unix.CLOCK_MONOTONIC_RAW} {
+		ref, err := NewReference(clockId)
+		require.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		elapsed, err := ref.Elapsed()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+
+		now, err := ref.Now()
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), now, 10*time.Millisecond)
+	}
+}
+
+func TestReference_survivesState(t *testing.T) {
+	ref, err := NewReference(unix.CLOCK_MONOTONIC)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(ref)
+	require.NoError(t, err)
+	var restored Reference
+	require.NoError(t, json.Unmarshal(data, &restored))
+
+	assert.True(t, ref.WallTime.Equal(restored.WallTime))
+	// the wall time must behave the same before and after persisting, so it has no monotonic reading
+	assert.Equal(t, ref.WallTime.Round(0), ref.WallTime)
+	assert.Equal(t, ref.ClockId, restored.ClockId)
+	assert.Equal(t, ref.ClockNanos, restored.ClockNanos)
+}
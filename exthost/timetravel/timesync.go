@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/go-ps"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-host/exthost/hostns"
+)
+
+const (
+	chronyd  = "chronyd"
+	timesync = "systemd-timesyncd"
+	ntpd     = "ntpd"
+)
+
+// TimeSyncDaemon is a time synchronization daemon running on the host
+type TimeSyncDaemon struct {
+	Name string
+	// Unit is the systemd unit of the daemon, if run by systemd
+	Unit string
+	// Sources are the chronyd sources which were online and set offline by PauseTimeSync
+	Sources []string
+}
+
+func (d TimeSyncDaemon) String() string {
+	if d.Unit != "" {
+		return fmt.Sprintf("%s (%s)", d.Name, d.Unit)
+	}
+	return d.Name
+}
+
+var runHostCommand = hostns.Run
+
+var listProcesses = ps.Processes
+
+var readCgroup = func(pid int) (string, error) {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	return string(b), err
+}
+
+// DetectTimeSyncDaemons returns the time synchronization daemons running on the host
+func DetectTimeSyncDaemons() ([]TimeSyncDaemon, error) {
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var daemons []TimeSyncDaemon
+	seen := make(map[string]bool)
+	for _, p := range processes {
+		name := p.Executable()
+		if (name != chronyd && name != timesync && name != ntpd) || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		daemon := TimeSyncDaemon{Name: name}
+		if cgroup, err := readCgroup(p.Pid()); err == nil {
+			daemon.Unit = unitOfCgroup(cgroup)
+		}
+		daemons = append(daemons, daemon)
+	}
+	return daemons, nil
+}
+
+// PauseTimeSync pauses the daemons and returns the paused ones. The online sources of chronyd are set offline via
+// chronyc, other daemons lack such an interface and their systemd unit is stopped. Daemons not run by systemd are skipped.
+func PauseTimeSync(ctx context.Context, daemons []TimeSyncDaemon) ([]TimeSyncDaemon, error) {
+	var paused []TimeSyncDaemon
+	for _, d := range daemons {
+		var err error
+		switch {
+		case d.Name == chronyd:
+			d.Sources, err = offlineChronySources(ctx)
+			if len(d.Sources) == 0 && err == nil {
+				log.Info().Msg("No chronyd source online, nothing to pause")
+				continue
+			}
+		case d.Unit != "":
+			_, err = runHostCommand(ctx, "systemctl", "stop", "--", d.Unit)
+		default:
+			log.Warn().Str("daemon", d.Name).Msg("Cannot pause time sync daemon not run by systemd")
+			continue
+		}
+		if err != nil {
+			if len(d.Sources) > 0 {
+				paused = append(paused, d)
+			}
+			return paused, fmt.Errorf("failed to pause %s: %w", d, err)
+		}
+		paused = append(paused, d)
+	}
+	return paused, nil
+}
+
+// ResumeTimeSync resumes the daemons paused by PauseTimeSync
+func ResumeTimeSync(ctx context.Context, paused []TimeSyncDaemon) error {
+	var errs []error
+	for _, d := range paused {
+		var err error
+		if d.Name == chronyd {
+			for _, source := range d.Sources {
+				if _, sourceErr := runHostCommand(ctx, "chronyc", "online", source); sourceErr != nil {
+					err = errors.Join(err, sourceErr)
+				}
+			}
+		} else {
+			_, err = runHostCommand(ctx, "systemctl", "start", "--", d.Unit)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resume %s: %w", d, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// offlineChronySources sets the online NTP sources of chronyd offline one by one and returns them. chronyc doesn't
+// report whether a source is online, so the number of online sources is compared before and after each source.
+func offlineChronySources(ctx context.Context) ([]string, error) {
+	out, err := runHostCommand(ctx, "chronyc", "-n", "-c", "sources")
+	if err != nil {
+		return nil, err
+	}
+	online, err := chronyOnlineSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var offlined []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, ",")
+		// reference clocks (#) can't be set offline
+		if len(fields) < 3 || fields[0] == "#" {
+			continue
+		}
+		if online == 0 {
+			break
+		}
+		if _, err := runHostCommand(ctx, "chronyc", "offline", fields[2]); err != nil {
+			return offlined, err
+		}
+		after, err := chronyOnlineSources(ctx)
+		if err != nil {
+			return offlined, err
+		}
+		if after < online {
+			offlined = append(offlined, fields[2])
+		}
+		online = after
+	}
+	return offlined, nil
+}
+
+// chronyOnlineSources returns the number of online sources from the csv output of chronyc activity
+func chronyOnlineSources(ctx context.Context) (int, error) {
+	out, err := runHostCommand(ctx, "chronyc", "-c", "activity")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if fields := strings.Split(line, ","); len(fields) >= 2 {
+			return strconv.Atoi(fields[0])
+		}
+	}
+	return 0, fmt.Errorf("unexpected output of chronyc activity: %s", out)
+}
+
+// unitOfCgroup returns the systemd service from the contents of /proc/<pid>/cgroup, e.g. 0::/system.slice/chrony.service
+func unitOfCgroup(cgroup string) string {
+	for _, line := range strings.Split(cgroup, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || (parts[0] != "0" && !strings.Contains(parts[1], "name=systemd")) {
+			continue
+		}
+		if unit := path.Base(parts[2]); strings.HasSuffix(unit, ".service") {
+			return unit
+		}
+	}
+	return ""
+}
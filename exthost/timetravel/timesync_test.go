@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/go-ps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProcess struct {
+	pid  int
+	name string
+}
+
+func (p fakeProcess) Pid() int           { return p.pid }
+func (p fakeProcess) PPid() int          { return 1 }
+func (p fakeProcess) Executable() string { return p.name }
+
+func fakeHostCommands(t *testing.T, fail string) *[]string {
+	oldRunHostCommand := runHostCommand
+	t.Cleanup(func() {
+		runHostCommand = oldRunHostCommand
+	})
+	var commands []string
+	runHostCommand = func(ctx context.Context, name string, arg ...string) (string, error) {
+		command := strings.Join(append([]string{name}, arg...), " ")
+		if command == fail {
+			return "", errors.New("failed")
+		}
+		commands = append(commands, command)
+		return "", nil
+	}
+	return &commands
+}
+
+func TestDetectTimeSyncDaemons(t *testing.T) {
+	oldListProcesses, oldReadCgroup := listProcesses, readCgroup
+	t.Cleanup(func() {
+		listProcesses, readCgroup = oldListProcesses, oldReadCgroup
+	})
+	listProcesses = func() ([]ps.Process, error) {
+		return []ps.Process{
+			fakeProcess{1, "systemd"},
+			fakeProcess{100, "chronyd"},
+			fakeProcess{101, "chronyd"},
+			fakeProcess{200, "ntpd"},
+		}, nil
+	}
+	readCgroup = func(pid int) (string, error) {
+		if pid == 100 {
+			return "0::/system.slice/chrony.service\n", nil
+		}
+		return "", errors.New("no such process")
+	}
+
+	daemons, err := DetectTimeSyncDaemons()
+	require.NoError(t, err)
+	assert.Equal(t, []TimeSyncDaemon{{Name: "chronyd", Unit: "chrony.service"}, {Name: "ntpd"}}, daemons)
+}
+
+// fakeChrony fakes chronyc with the given sources, of which only the online ones are counted by chronyc activity
+func fakeChrony(t *testing.T, sources string, online map[string]bool, fail string) *[]string {
+	oldRunHostCommand := runHostCommand
+	t.Cleanup(func() {
+		runHostCommand = oldRunHostCommand
+	})
+	var commands []string
+	runHostCommand = func(ctx context.Context, name string, arg ...string) (string, error) {
+		command := strings.Join(append([]string{name}, arg...), " ")
+		if command == fail {
+			return "", errors.New("failed")
+		}
+		switch {
+		case command == "chronyc -n -c sources":
+			return sources, nil
+		case command == "chronyc -c activity":
+			count := 0
+			for _, o := range online {
+				if o {
+					count++
+				}
+			}
+			return fmt.Sprintf("%d,%d,0,0,0\n", count, len(online)-count), nil
+		case name == "chronyc" && len(arg) == 2:
+			online[arg[1]] = arg[0] == "online"
+		}
+		commands = append(commands, command)
+		return "", nil
+	}
+	return &commands
+}
+
+const chronySources = "^,*,192.0.2.1,2,6,377,38,0.000012,0.000034,0.012\n" +
+	"^,?,192.0.2.2,0,6,0,-,0.0,0.0,0.0\n" +
+	"#,*,PPS0,0,4,377,12,0.0,0.0,0.0\n" +
+	"^,+,192.0.2.3,2,6,377,40,0.0,0.0,0.0\n"
+
+func TestPauseTimeSync(t *testing.T) {
+	online := map[string]bool{"192.0.2.1": true, "192.0.2.2": false, "192.0.2.3": true}
+	commands := fakeChrony(t, chronySources, online, "")
+
+	paused, err := PauseTimeSync(context.Background(), []TimeSyncDaemon{
+		{Name: "chronyd", Unit: "chronyd.service"},
+		{Name: "systemd-timesyncd", Unit: "systemd-timesyncd.service"},
+		{Name: "ntpd"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []TimeSyncDaemon{{Name: "chronyd", Unit: "chronyd.service", Sources: []string{"192.0.2.1", "192.0.2.3"}}, {Name: "systemd-timesyncd", Unit: "systemd-timesyncd.service"}}, paused)
+	assert.Equal(t, []string{"chronyc offline 192.0.2.1", "chronyc offline 192.0.2.2", "chronyc offline 192.0.2.3", "systemctl stop -- systemd-timesyncd.service"}, *commands)
+
+	*commands = nil
+	require.NoError(t, ResumeTimeSync(context.Background(), paused))
+	assert.Equal(t, []string{"chronyc online 192.0.2.1", "chronyc online 192.0.2.3", "systemctl start -- systemd-timesyncd.service"}, *commands)
+	assert.Equal(t, map[string]bool{"192.0.2.1": true, "192.0.2.2": false, "192.0.2.3": true}, online, "the offline source must stay offline")
+}
+
+func TestPauseTimeSync_skipsChronyWithoutOnlineSources(t *testing.T) {
+	commands := fakeChrony(t, chronySources, map[string]bool{"192.0.2.1": false, "192.0.2.2": false, "192.0.2.3": false}, "")
+
+	paused, err := PauseTimeSync(context.Background(), []TimeSyncDaemon{{Name: "chronyd"}})
+	require.NoError(t, err)
+	assert.Empty(t, paused)
+	assert.Empty(t, *commands)
+}
+
+func TestPauseTimeSync_returnsPausedOnError(t *testing.T) {
+	fakeHostCommands(t, "systemctl stop -- ntp.service")
+
+	paused, err := PauseTimeSync(context.Background(), []TimeSyncDaemon{{Name: "systemd-timesyncd", Unit: "systemd-timesyncd.service"}, {Name: "ntpd", Unit: "ntp.service"}})
+	assert.ErrorContains(t, err, "failed to pause ntpd (ntp.service)")
+	assert.Equal(t, []TimeSyncDaemon{{Name: "systemd-timesyncd", Unit: "systemd-timesyncd.service"}}, paused)
+}
+
+func TestPauseTimeSync_returnsOfflinedChronySourcesOnError(t *testing.T) {
+	online := map[string]bool{"192.0.2.1": true, "192.0.2.2": false, "192.0.2.3": true}
+	fakeChrony(t, chronySources, online, "chronyc offline 192.0.2.3")
+
+	paused, err := PauseTimeSync(context.Background(), []TimeSyncDaemon{{Name: "chronyd"}})
+	assert.ErrorContains(t, err, "failed to pause chronyd")
+	assert.Equal(t, []TimeSyncDaemon{{Name: "chronyd", Sources: []string{"192.0.2.1"}}}, paused)
+}
+
+func Test_unitOfCgroup(t *testing.T) {
+	assert.Equal(t, "chrony.service", unitOfCgroup("0::/system.slice/chrony.service\n"))
+	assert.Equal(t, "ntp.service", unitOfCgroup("12:pids:/system.slice/ntp.service\n1:name=systemd:/system.slice/ntp.service\n0::/system.slice/ntp.service\n"))
+	assert.Equal(t, "systemd-timesyncd.service", unitOfCgroup("1:name=systemd:/system.slice/systemd-timesyncd.service\n"))
+	assert.Equal(t, "", unitOfCgroup("0::/kubepods/burstable/pod123/abc\n"))
+	assert.Equal(t, "", unitOfCgroup(""))
+}
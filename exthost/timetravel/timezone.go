@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/steadybit/extension-host/exthost/hostns"
+)
+
+const (
+	localtimePath    = "/etc/localtime"
+	localtimeTmpPath = "/etc/.localtime.steadybit"
+	zoneinfoDir      = "/usr/share/zoneinfo"
+)
+
+// LocaltimeBackup holds the original /etc/localtime of the host, either the target of the symlink or the file's content and metadata
+type LocaltimeBackup struct {
+	Exists  bool
+	Target  string
+	Content []byte
+	Mode    uint32
+	Uid     int
+	Gid     int
+}
+
+// runHostShell runs the script as root in the host's namespaces, so /etc/localtime keeps being owned by root
+var runHostShell = func(ctx context.Context, stdin []byte, script string, arg ...string) error {
+	cmd := hostns.Command(ctx, "sh", append([]string{"-c", script, "sh"}, arg...)...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Timezone returns the name of the timezone the backup links to, if known
+func (b LocaltimeBackup) Timezone() string {
+	if _, tz, found := strings.Cut(b.Target, "zoneinfo/"); found {
+		return tz
+	}
+	if !b.Exists {
+		return "UTC"
+	}
+	return "unknown"
+}
+
+// BackupLocaltime reads the host's /etc/localtime
+func BackupLocaltime() (LocaltimeBackup, error) {
+	p := hostfs.Path(localtimePath)
+	fi, err := os.Lstat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return LocaltimeBackup{}, nil
+	} else if err != nil {
+		return LocaltimeBackup{}, err
+	}
+
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(p)
+		if err != nil {
+			return LocaltimeBackup{}, err
+		}
+		return LocaltimeBackup{Exists: true, Target: target}, nil
+	}
+
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return LocaltimeBackup{}, err
+	}
+	st := fi.Sys().(*syscall.Stat_t)
+	return LocaltimeBackup{Exists: true, Content: content, Mode: st.Mode & 07777, Uid: int(st.Uid), Gid: int(st.Gid)}, nil
+}
+
+// CheckTimezone checks the timezone is installed on the host, e.g. Europe/Berlin
+func CheckTimezone(tz string) error {
+	if tz == "" || path.IsAbs(tz) || path.Clean(tz) != tz || strings.HasPrefix(tz, "..") {
+		return fmt.Errorf("invalid timezone '%s'", tz)
+	}
+	fi, err := os.Stat(hostfs.Path(path.Join(zoneinfoDir, tz)))
+	if err != nil || !fi.Mode().IsRegular() {
+		return fmt.Errorf("timezone %s not found in %s of the host", tz, zoneinfoDir)
+	}
+	return nil
+}
+
+// SetTimezone replaces the host's /etc/localtime by a link to the timezone
+func SetTimezone(ctx context.Context, tz string) error {
+	if err := CheckTimezone(tz); err != nil {
+		return err
+	}
+	return replaceLocaltimeByLink(ctx, path.Join(zoneinfoDir, tz))
+}
+
+// RestoreLocaltime restores the host's /etc/localtime from the backup
+func RestoreLocaltime(ctx context.Context, b LocaltimeBackup) error {
+	if !b.Exists {
+		if err := runHostShell(ctx, nil, `rm -f -- "$1"`, localtimePath); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", localtimePath, err)
+		}
+		return nil
+	}
+	if b.Target != "" {
+		return replaceLocaltimeByLink(ctx, b.Target)
+	}
+	// the content is written to a temporary file first, which atomically replaces /etc/localtime with the original owner and mode
+	script := `trap 'rm -f -- "$1"' EXIT; cat > "$1" && chown -- "$2" "$1" && chmod -- "$3" "$1" && mv -Tf -- "$1" "$4"`
+	if err := runHostShell(ctx, b.Content, script, localtimeTmpPath, fmt.Sprintf("%d:%d", b.Uid, b.Gid), fmt.Sprintf("%04o", b.Mode), localtimePath); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", localtimePath, err)
+	}
+	return nil
+}
+
+// replaceLocaltimeByLink atomically replaces /etc/localtime by a symlink to the target
+func replaceLocaltimeByLink(ctx context.Context, target string) error {
+	script := `trap 'rm -f -- "$2"' EXIT; ln -sfn -- "$1" "$2" && mv -Tf -- "$2" "$3"`
+	if err := runHostShell(ctx, nil, script, target, localtimeTmpPath, localtimePath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", localtimePath, err)
+	}
+	return nil
+}
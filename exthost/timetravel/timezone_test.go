@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package timetravel
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steadybit/extension-host/exthost/hostfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeHostRoot(t *testing.T) string {
+	oldRootPath := hostfs.RootPath
+	oldRunHostShell := runHostShell
+	hostfs.RootPath = t.TempDir()
+	// runs the script locally, the files in /etc are mapped into the fake root while link targets are kept
+	runHostShell = func(ctx context.Context, stdin []byte, script string, arg ...string) error {
+		mapped := []string{"-c", script, "sh"}
+		for _, a := range arg {
+			if strings.HasPrefix(a, "/etc/") {
+				a = hostfs.Path(a)
+			}
+			mapped = append(mapped, a)
+		}
+		cmd := exec.CommandContext(ctx, "sh", mapped...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		return cmd.Run()
+	}
+	t.Cleanup(func() {
+		hostfs.RootPath = oldRootPath
+		runHostShell = oldRunHostShell
+	})
+
+	for _, tz := range []string{"Europe/Berlin", "America/New_York"} {
+		p := filepath.Join(hostfs.RootPath, zoneinfoDir, tz)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte("TZif "+tz), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(hostfs.RootPath, "etc"), 0755))
+	return filepath.Join(hostfs.RootPath, localtimePath)
+}
+
+func TestSetTimezone_restoresSymlink(t *testing.T) {
+	localtime := fakeHostRoot(t)
+	require.NoError(t, os.Symlink("../usr/share/zoneinfo/Europe/Berlin", localtime))
+
+	backup, err := BackupLocaltime()
+	require.NoError(t, err)
+	assert.Equal(t, LocaltimeBackup{Exists: true, Target: "../usr/share/zoneinfo/Europe/Berlin"}, backup)
+	assert.Equal(t, "Europe/Berlin", backup.Timezone())
+
+	require.NoError(t, SetTimezone(context.Background(), "America/New_York"))
+	target, err := os.Readlink(localtime)
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/share/zoneinfo/America/New_York", target)
+
+	require.NoError(t, RestoreLocaltime(context.Background(), backup))
+	target, err = os.Readlink(localtime)
+	require.NoError(t, err)
+	assert.Equal(t, "../usr/share/zoneinfo/Europe/Berlin", target)
+}
+
+func TestSetTimezone_restoresFile(t *testing.T) {
+	localtime := fakeHostRoot(t)
+	require.NoError(t, os.WriteFile(localtime, []byte("TZif UTC"), 0640))
+	require.NoError(t, os.Chmod(localtime, 0640))
+
+	backup, err := BackupLocaltime()
+	require.NoError(t, err)
+	assert.Equal(t, "unknown", backup.Timezone())
+	assert.Equal(t, uint32(0640), backup.Mode)
+	assert.Equal(t, os.Getuid(), backup.Uid)
+
+	require.NoError(t, SetTimezone(context.Background(), "Europe/Berlin"))
+	require.NoError(t, RestoreLocaltime(context.Background(), backup))
+
+	fi, err := os.Lstat(localtime)
+	require.NoError(t, err)
+	assert.True(t, fi.Mode().IsRegular())
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+	content, err := os.ReadFile(localtime)
+	require.NoError(t, err)
+	assert.Equal(t, "TZif UTC", string(content))
+}
+
+func TestSetTimezone_restoresMissing(t *testing.T) {
+	localtime := fakeHostRoot(t)
+
+	backup, err := BackupLocaltime()
+	require.NoError(t, err)
+	assert.False(t, backup.Exists)
+
+	require.NoError(t, SetTimezone(context.Background(), "Europe/Berlin"))
+	require.NoError(t, RestoreLocaltime(context.Background(), backup))
+	_, err = os.Lstat(localtime)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCheckTimezone(t *testing.T) {
+	fakeHostRoot(t)
+
+	assert.NoError(t, CheckTimezone("Europe/Berlin"))
+	assert.ErrorContains(t, CheckTimezone("Europe/Atlantis"), "not found")
+	assert.ErrorContains(t, CheckTimezone("Europe"), "not found")
+	assert.ErrorContains(t, CheckTimezone("../../etc/passwd"), "invalid timezone")
+	assert.ErrorContains(t, CheckTimezone("/etc/passwd"), "invalid timezone")
+	assert.ErrorContains(t, CheckTimezone(""), "invalid timezone")
+}
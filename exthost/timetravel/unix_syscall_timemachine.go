@@ -1,48 +1,64 @@
 package timetravel
 
 import (
-	"errors"
+	"fmt"
 	"github.com/rs/zerolog/log"
-	"syscall"
+	"golang.org/x/sys/unix"
 	"time"
 )
 
-func AdjustTime(offset time.Duration, negate bool) error {
-	tp := syscall.Timeval{}
-	err := syscall.Gettimeofday(&tp)
-	initialTime := tp.Sec
-	log.Info().Msgf("Current time: %d", tp.Sec)
-	if err != nil {
-		log.Err(err).Msg("Could not change time offset - Gettimeofday")
-		return err
+// offsetTolerance is the allowed difference between the requested and the applied offset
+const offsetTolerance = 10 * time.Millisecond
+
+// AdjustTime moves the wall clock by the given offset, which may be negative, with microsecond precision.
+// applied reports whether the clock was set, it is true even if the applied offset couldn't be verified.
+func AdjustTime(offset time.Duration) (applied bool, err error) {
+	var wallBefore, monoBefore, wallAfter, monoAfter unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &monoBefore); err != nil {
+		return false, fmt.Errorf("could not read monotonic clock: %w", err)
 	}
-	seconds := int64(offset.Seconds())
-	if negate {
-		seconds = -seconds
+	tv := unix.Timeval{}
+	if err := unix.Gettimeofday(&tv); err != nil {
+		log.Err(err).Msg("Could not change time offset - Gettimeofday")
+		return false, err
 	}
-	log.Info().Msgf("Adjusting time by %d seconds", seconds)
-	tp.Sec += seconds
-	err = syscall.Settimeofday(&tp)
-	if err != nil {
+	wallBefore = unix.NsecToTimespec(tv.Nano())
+	log.Info().Msgf("Current time: %s", time.Unix(0, tv.Nano()).UTC())
+
+	tv = addOffset(tv, offset)
+	log.Info().Msgf("Adjusting time by %s", offset.Truncate(time.Microsecond))
+	if err := unix.Settimeofday(&tv); err != nil {
 		log.Err(err).Msg("Could not change time offset - Settimeofday")
-		return err
+		return false, err
+	}
+
+	if err := unix.ClockGettime(unix.CLOCK_REALTIME, &wallAfter); err != nil {
+		return true, fmt.Errorf("could not read wall clock: %w", err)
+	}
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &monoAfter); err != nil {
+		return true, fmt.Errorf("could not read monotonic clock: %w", err)
 	}
-	newTime := tp.Sec
-	log.Info().Msgf("New time: %d", tp.Sec)
-	diff := newTime - initialTime
+	log.Info().Msgf("New time: %s", time.Unix(0, wallAfter.Nano()).UTC())
+
+	return true, verifyOffset(offset, time.Duration(wallAfter.Nano()-wallBefore.Nano()), time.Duration(monoAfter.Nano()-monoBefore.Nano()))
+}
+
+// addOffset adds the offset truncated to microseconds to the timeval, keeping the microseconds normalized
+func addOffset(tv unix.Timeval, offset time.Duration) unix.Timeval {
+	return unix.NsecToTimeval(tv.Nano() + offset.Truncate(time.Microsecond).Nanoseconds())
+}
+
+// verifyOffset checks the offset was applied, by subtracting the time passed according to the monotonic clock from the
+// change of the wall clock.
+func verifyOffset(offset, wallDelta, monoDelta time.Duration) error {
+	applied := wallDelta - monoDelta
+	diff := applied - offset.Truncate(time.Microsecond)
 	if diff < 0 {
 		diff = -diff
 	}
-	log.Info().Msgf("Time difference: %d", diff)
-	normalizedOffset := offset.Seconds()
-	if offset < 0 {
-		normalizedOffset = -normalizedOffset
-	}
-	minDiff := normalizedOffset * 0.8
-	maxDiff := normalizedOffset * 1.2
-	if float64(diff) >= minDiff && float64(diff) <= maxDiff {
-		return nil
-	} else {
-		return errors.New("time offset not applied")
+	log.Info().Msgf("Applied offset: %s, difference to requested: %s", applied, diff)
+	if diff > offsetTolerance {
+		return fmt.Errorf("time offset not applied, requested %s, applied %s", offset, applied)
 	}
+	return nil
 }
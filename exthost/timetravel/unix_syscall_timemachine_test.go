@@ -0,0 +1,59 @@
+package timetravel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func Test_addOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		tv     unix.Timeval
+		offset time.Duration
+		want   unix.Timeval
+	}{
+		{name: "seconds", tv: unix.Timeval{Sec: 1000, Usec: 500}, offset: 3 * time.Second, want: unix.Timeval{Sec: 1003, Usec: 500}},
+		{name: "negative seconds", tv: unix.Timeval{Sec: 1000, Usec: 500}, offset: -3 * time.Second, want: unix.Timeval{Sec: 997, Usec: 500}},
+		{name: "milliseconds", tv: unix.Timeval{Sec: 1000, Usec: 500}, offset: 250 * time.Millisecond, want: unix.Timeval{Sec: 1000, Usec: 250_500}},
+		{name: "microseconds carry", tv: unix.Timeval{Sec: 1000, Usec: 999_999}, offset: 2 * time.Microsecond, want: unix.Timeval{Sec: 1001, Usec: 1}},
+		{name: "negative microseconds borrow", tv: unix.Timeval{Sec: 1000, Usec: 1}, offset: -2 * time.Microsecond, want: unix.Timeval{Sec: 999, Usec: 999_999}},
+		{name: "negative milliseconds", tv: unix.Timeval{Sec: 1000, Usec: 100_000}, offset: -1500 * time.Millisecond, want: unix.Timeval{Sec: 998, Usec: 600_000}},
+		{name: "nanoseconds are truncated", tv: unix.Timeval{Sec: 1000}, offset: 1999 * time.Nanosecond, want: unix.Timeval{Sec: 1000, Usec: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, addOffset(tt.tv, tt.offset))
+		})
+	}
+}
+
+func Test_verifyOffset(t *testing.T) {
+	tests := []struct {
+		name      string
+		offset    time.Duration
+		wallDelta time.Duration
+		monoDelta time.Duration
+		wantErr   bool
+	}{
+		{name: "applied", offset: time.Hour, wallDelta: time.Hour + 2*time.Millisecond, monoDelta: 2 * time.Millisecond},
+		{name: "negative applied", offset: -time.Hour, wallDelta: -time.Hour + 2*time.Millisecond, monoDelta: 2 * time.Millisecond},
+		{name: "sub second applied", offset: 1500 * time.Microsecond, wallDelta: 1600 * time.Microsecond, monoDelta: 100 * time.Microsecond},
+		{name: "not applied", offset: time.Second, wallDelta: 2 * time.Millisecond, monoDelta: 2 * time.Millisecond, wantErr: true},
+		{name: "wrong direction", offset: -time.Second, wallDelta: time.Second, monoDelta: 0, wantErr: true},
+		{name: "within tolerance", offset: time.Second, wallDelta: time.Second + 9*time.Millisecond, monoDelta: 0},
+		{name: "beyond tolerance", offset: time.Second, wallDelta: time.Second + 11*time.Millisecond, monoDelta: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyOffset(tt.offset, tt.wallDelta, tt.monoDelta)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
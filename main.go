@@ -4,6 +4,8 @@
 package main
 
 import (
+	"os"
+
 	_ "github.com/KimMachineGun/automemlimit" // By default, it sets `GOMEMLIMIT` to 90% of cgroup's memory limit.
 	"github.com/rs/zerolog"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
@@ -13,6 +15,7 @@ import (
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-host/config"
 	"github.com/steadybit/extension-host/exthost"
+	"github.com/steadybit/extension-host/exthost/fsfault"
 	"github.com/steadybit/extension-host/exthost/resources"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/exthealth"
@@ -33,6 +36,11 @@ func main() {
 	//  - to set the log level to debug, set the environment variable STEADYBIT_LOG_LEVEL="debug"
 	extlogging.InitZeroLog()
 
+	// The extension runs itself in the host's mount namespace to serve the filesystem of the filesystem fault attack.
+	if len(os.Args) > 1 && os.Args[1] == fsfault.Subcommand {
+		os.Exit(fsfault.Main(os.Args[2:]))
+	}
+
 	resources.AdjustOOMScoreAdj()
 
 	// Build information is set at compile-time. This line writes the build information to the log.
@@ -60,12 +68,21 @@ func main() {
 	// for your extension. You might want to change these because the names do not fit, or because
 	// you do not have a need for all of them.
 	discovery_kit_sdk.Register(exthost.NewHostDiscovery())
+	discovery_kit_sdk.Register(exthost.NewServiceDiscovery())
 	action_kit_sdk.RegisterAction(exthost.NewStressCpuAction(r))
 	action_kit_sdk.RegisterAction(exthost.NewCpuSpeedAction())
+	action_kit_sdk.RegisterAction(exthost.NewCpuOfflineAction())
 	action_kit_sdk.RegisterAction(exthost.NewStressMemoryAction(r))
 	action_kit_sdk.RegisterAction(exthost.NewStressIoAction(r))
 	action_kit_sdk.RegisterAction(exthost.NewTimetravelAction(r))
+	action_kit_sdk.RegisterAction(exthost.NewTimeNamespaceAction())
+	action_kit_sdk.RegisterAction(exthost.NewTimeNamespaceServiceAction())
+	action_kit_sdk.RegisterAction(exthost.NewClockEventAction())
 	action_kit_sdk.RegisterAction(exthost.NewStopProcessAction())
+	action_kit_sdk.RegisterAction(exthost.NewStopServiceProcessesAction())
+	action_kit_sdk.RegisterAction(exthost.NewPauseProcessAction())
+	action_kit_sdk.RegisterAction(exthost.NewPauseServiceAction())
+	action_kit_sdk.RegisterAction(exthost.NewStopServiceAction())
 	action_kit_sdk.RegisterAction(exthost.NewShutdownAction())
 	action_kit_sdk.RegisterAction(exthost.NewNetworkBlackholeContainerAction(r))
 	action_kit_sdk.RegisterAction(exthost.NewNetworkLimitBandwidthContainerAction(r))
@@ -75,6 +92,14 @@ func main() {
 	action_kit_sdk.RegisterAction(exthost.NewNetworkPackageLossContainerAction(r))
 	action_kit_sdk.RegisterAction(exthost.NewFillDiskHostAction(r))
 	action_kit_sdk.RegisterAction(exthost.NewFillMemoryHostAction(r))
+	action_kit_sdk.RegisterAction(exthost.NewIoLatencyAction())
+	action_kit_sdk.RegisterAction(exthost.NewIoThrottleAction())
+	action_kit_sdk.RegisterAction(exthost.NewIoThrottleServiceAction())
+	action_kit_sdk.RegisterAction(exthost.NewLimitCpuAction())
+	action_kit_sdk.RegisterAction(exthost.NewLimitCpuServiceAction())
+	action_kit_sdk.RegisterAction(exthost.NewReadOnlyFsAction())
+	action_kit_sdk.RegisterAction(exthost.NewFsFaultAction())
+	action_kit_sdk.RegisterAction(exthost.NewCorruptFileAction())
 
 	//This will install a signal handler, that will stop active actions when receiving a SIGURS1, SIGTERM or SIGINT
 	extsignals.ActivateSignalHandlers()